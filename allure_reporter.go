@@ -0,0 +1,246 @@
+package spec
+
+import (
+	"crypto/md5"
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+)
+
+// A reporter which writes Allure 2 results into a directory: a
+// `*-result.json` file for every spec, and a `*-container.json` file for every
+// suite with children. A spec's logs and captured output are attached as
+// `*-attachment.txt` files. The first error writing them is kept, and returned
+// from `Err`.
+type AllureReporter struct {
+	dir      string
+	hostname string
+	stack    []*allureContainer
+	current  *allureResult
+	err      error
+}
+
+type allureLabel struct {
+	Name  string `json:"name"`
+	Value string `json:"value"`
+}
+
+type allureStatusDetails struct {
+	Message string `json:"message,omitempty"`
+	Trace   string `json:"trace,omitempty"`
+}
+
+type allureAttachment struct {
+	Name   string `json:"name"`
+	Source string `json:"source"`
+	Type   string `json:"type"`
+}
+
+type allureResult struct {
+	UUID          string               `json:"uuid"`
+	HistoryID     string               `json:"historyId"`
+	TestCaseID    string               `json:"testCaseId"`
+	FullName      string               `json:"fullName"`
+	Name          string               `json:"name"`
+	Status        string               `json:"status"`
+	StatusDetails *allureStatusDetails `json:"statusDetails,omitempty"`
+	Stage         string               `json:"stage"`
+	Start         int64                `json:"start"`
+	Stop          int64                `json:"stop"`
+	Labels        []*allureLabel       `json:"labels"`
+	Attachments   []*allureAttachment  `json:"attachments"`
+}
+
+type allureContainer struct {
+	UUID     string   `json:"uuid"`
+	Name     string   `json:"name"`
+	Children []string `json:"children"`
+	Start    int64    `json:"start"`
+	Stop     int64    `json:"stop"`
+}
+
+func Allure(dir string) *AllureReporter {
+	hostname, _ := os.Hostname()
+	return &AllureReporter{
+		dir:      dir,
+		hostname: hostname,
+		stack:    make([]*allureContainer, 0),
+	}
+}
+
+// The first error encountered writing results, if any.
+func (a *AllureReporter) Err() error {
+	return a.err
+}
+
+func (a *AllureReporter) Start(s *suite) {
+	path := make([]string, 0, len(a.stack)+1)
+	for _, container := range a.stack {
+		path = append(path, container.Name)
+	}
+	path = append(path, s.Name)
+	fullName := strings.Join(path, " > ")
+	id := md5.Sum([]byte(fullName))
+
+	a.current = &allureResult{
+		UUID:        allureUUID(),
+		HistoryID:   hex.EncodeToString(id[:]),
+		TestCaseID:  hex.EncodeToString(id[:]),
+		FullName:    fullName,
+		Name:        s.Name,
+		Stage:       "running",
+		Start:       unixMillis(time.Now()),
+		Labels:      a.labels(path[:len(path)-1]),
+		Attachments: make([]*allureAttachment, 0),
+	}
+	if len(a.stack) > 0 {
+		parent := a.stack[len(a.stack)-1]
+		parent.Children = append(parent.Children, a.current.UUID)
+	}
+}
+
+func (a *AllureReporter) Pass(s *suite) {
+	a.finish(s, "passed", nil)
+}
+
+func (a *AllureReporter) Fail(s *suite, errs []*TestError) {
+	messages := make([]string, len(errs))
+	traces := make([]string, len(errs))
+	for i, err := range errs {
+		messages[i] = err.Error()
+		traces[i] = fmt.Sprintf("%s:%d", err.File, err.Line)
+		if src, e := err.Source(); e == nil {
+			traces[i] += "\n    " + src
+		}
+	}
+	a.finish(s, "failed", &allureStatusDetails{
+		Message: strings.Join(messages, "\n"),
+		Trace:   strings.Join(traces, "\n"),
+	})
+}
+
+func (a *AllureReporter) Skip(s *suite, skip *TestError) {
+	a.finish(s, "skipped", &allureStatusDetails{
+		Message: skip.Error(),
+	})
+}
+
+func (a *AllureReporter) Descend(s *suite) {
+	a.stack = append(a.stack, &allureContainer{
+		UUID:     allureUUID(),
+		Name:     s.Name,
+		Children: make([]string, 0),
+		Start:    unixMillis(time.Now()),
+	})
+}
+
+func (a *AllureReporter) Ascend(*suite) {
+	container := a.stack[len(a.stack)-1]
+	a.stack = a.stack[0 : len(a.stack)-1]
+
+	if len(container.Children) == 0 {
+		return
+	}
+	container.Stop = unixMillis(time.Now())
+	a.write(container.UUID+"-container.json", container)
+}
+
+func (a *AllureReporter) Begin() {
+	a.err = nil
+	if err := os.MkdirAll(a.dir, 0755); err != nil {
+		a.err = err
+	}
+}
+
+func (a *AllureReporter) Finish([]*SuiteFailure) {
+}
+
+func (a *AllureReporter) finish(s *suite, status string, details *allureStatusDetails) {
+	a.current.Status = status
+	a.current.StatusDetails = details
+	a.current.Stage = "finished"
+	a.current.Stop = a.current.Start + s.Stats.Duration.Nanoseconds()/1e6
+
+	logs := ""
+	for _, entry := range s.Logs {
+		logs += entry.String() + "\n"
+	}
+	a.attach("Logs", logs)
+	a.attach("Stdout", s.Stdout)
+	a.attach("Stderr", s.Stderr)
+
+	a.write(a.current.UUID+"-result.json", a.current)
+}
+
+// Attach text to the current result, unless there is none.
+func (a *AllureReporter) attach(name, text string) {
+	if text == "" {
+		return
+	}
+	source := allureUUID() + "-attachment.txt"
+	err := os.WriteFile(filepath.Join(a.dir, source), []byte(text), 0644)
+	if err != nil {
+		if a.err == nil {
+			a.err = err
+		}
+		return
+	}
+	a.current.Attachments = append(a.current.Attachments, &allureAttachment{
+		Name:   name,
+		Source: source,
+		Type:   "text/plain",
+	})
+}
+
+// Labels group results by the suites above them. Allure has three levels of
+// suite, so anything deeper is folded into the last.
+func (a *AllureReporter) labels(suites []string) []*allureLabel {
+	labels := []*allureLabel{
+		{Name: "framework", Value: "spec"},
+		{Name: "language", Value: "go"},
+		{Name: "host", Value: a.hostname},
+	}
+
+	var names []string
+	switch len(suites) {
+	case 0:
+	case 1:
+		names = []string{"suite"}
+	case 2:
+		names = []string{"parentSuite", "suite"}
+	default:
+		names = []string{"parentSuite", "suite", "subSuite"}
+	}
+	for i, name := range names {
+		value := suites[i]
+		if i == len(names)-1 {
+			value = strings.Join(suites[i:], " > ")
+		}
+		labels = append(labels, &allureLabel{Name: name, Value: value})
+	}
+	return labels
+}
+
+func (a *AllureReporter) write(name string, v interface{}) {
+	data, err := json.Marshal(v)
+	if err == nil {
+		err = os.WriteFile(filepath.Join(a.dir, name), data, 0644)
+	}
+	if err != nil && a.err == nil {
+		a.err = err
+	}
+}
+
+// A random (version 4) UUID.
+func allureUUID() string {
+	b := make([]byte, 16)
+	rand.Read(b)
+	b[6] = (b[6] & 0x0f) | 0x40
+	b[8] = (b[8] & 0x3f) | 0x80
+	return fmt.Sprintf("%x-%x-%x-%x-%x", b[0:4], b[4:6], b[6:8], b[8:10], b[10:])
+}
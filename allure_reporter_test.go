@@ -0,0 +1,100 @@
+package spec
+
+import (
+	"encoding/json"
+	"fmt"
+	"github.com/markchadwick/assert"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"testing"
+)
+
+func TestAllureResults(t *testing.T) {
+	dir := t.TempDir()
+	reporter := Allure(dir)
+	runFixture(reporter)
+	assert.That(t, reporter.Err()).IsNil()
+
+	results := make(map[string]*allureResult)
+	containers := make([]*allureContainer, 0)
+	files, _ := filepath.Glob(filepath.Join(dir, "*.json"))
+	for _, file := range files {
+		data, err := os.ReadFile(file)
+		assert.That(t, err).IsNil()
+		if strings.HasSuffix(file, "-container.json") {
+			container := new(allureContainer)
+			assert.That(t, json.Unmarshal(data, container)).IsNil()
+			containers = append(containers, container)
+		} else {
+			result := new(allureResult)
+			assert.That(t, json.Unmarshal(data, result)).IsNil()
+			results[result.Name] = result
+		}
+	}
+	assert.That(t, results).HasLen(6)
+	assert.That(t, containers).HasLen(2)
+	assert.That(t, results["skips"].Status).Equals("skipped")
+	assert.That(t, results["skips"].StatusDetails.Message).Equals("later")
+
+	failed := results["fails"]
+	assert.That(t, failed.FullName).Equals("Fixture suite > nests > fails")
+	assert.That(t, failed.Status).Equals("failed")
+	assert.That(t, failed.Stage).Equals("finished")
+	assert.That(t, failed.StatusDetails.Message).Equals("nope")
+	assert.That(t, strings.Contains(failed.StatusDetails.Trace, "reporter_test.go:")).
+		IsTrue()
+	assert.That(t, strings.HasSuffix(failed.StatusDetails.Trace,
+		"\n    "+`c.Failf("nope")`)).IsTrue()
+	assert.That(t, failed.Labels[3:]).Equals([]*allureLabel{
+		{Name: "parentSuite", Value: "Fixture suite"},
+		{Name: "suite", Value: "nests"},
+	})
+
+	children := make([]int, len(containers))
+	for i, container := range containers {
+		children[i] = len(container.Children)
+	}
+	sort.Ints(children)
+	assert.That(t, children).Equals([]int{1, 4})
+}
+
+func TestAllureAttachments(t *testing.T) {
+	dir := t.TempDir()
+	r := Runner(Suite("Allure output", func(c *C) {
+		c.Log("logged")
+		fmt.Print("printed")
+	}))
+	r.Capture = true
+	reporter := Allure(dir)
+	r.Run(reporter)
+	assert.That(t, reporter.Err()).IsNil()
+
+	files, _ := filepath.Glob(filepath.Join(dir, "*-result.json"))
+	assert.That(t, files).HasLen(1)
+	data, _ := os.ReadFile(files[0])
+	result := new(allureResult)
+	assert.That(t, json.Unmarshal(data, result)).IsNil()
+
+	attachments := result.Attachments
+	assert.That(t, attachments).HasLen(2)
+	assert.That(t, attachments[0].Name).Equals("Logs")
+	assert.That(t, attachments[1].Name).Equals("Stdout")
+	assert.That(t, attachments[1].Type).Equals("text/plain")
+
+	stdout, err := os.ReadFile(filepath.Join(dir, attachments[1].Source))
+	assert.That(t, err).IsNil()
+	assert.That(t, string(stdout)).Equals("printed")
+	logs, _ := os.ReadFile(filepath.Join(dir, attachments[0].Source))
+	assert.That(t, strings.HasSuffix(string(logs), ": logged\n")).IsTrue()
+}
+
+func TestAllureSubSuiteLabels(t *testing.T) {
+	labels := Allure("").labels([]string{"a", "b", "c", "d"})
+	assert.That(t, labels[3:]).Equals([]*allureLabel{
+		{Name: "parentSuite", Value: "a"},
+		{Name: "suite", Value: "b"},
+		{Name: "subSuite", Value: "c > d"},
+	})
+}
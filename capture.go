@@ -0,0 +1,77 @@
+package spec
+
+import (
+	"bytes"
+	"io"
+	"os"
+)
+
+// Output written to os.Stdout and os.Stderr while it is in place. Captures may
+// be nested, in which case the inner capture takes everything until it is
+// stopped, then the outer capture carries on.
+type capture struct {
+	stdout *capturedFile
+	stderr *capturedFile
+}
+
+// One of the files being captured, and the pipe standing in for it.
+type capturedFile struct {
+	saved *os.File
+	w     *os.File
+	buf   *bytes.Buffer
+	done  chan struct{}
+}
+
+// Swap os.Stdout and os.Stderr for pipes until `stop` is called. If a pipe
+// cannot be made, output is left alone and nothing will be captured.
+func startCapture() *capture {
+	c := &capture{}
+	if c.stdout = captureFile(os.Stdout); c.stdout != nil {
+		os.Stdout = c.stdout.w
+	}
+	if c.stderr = captureFile(os.Stderr); c.stderr != nil {
+		os.Stderr = c.stderr.w
+	}
+	return c
+}
+
+// Put back the original os.Stdout and os.Stderr, returning everything written
+// to them in the meantime.
+func (c *capture) stop() (stdout, stderr string) {
+	if c.stdout != nil {
+		os.Stdout = c.stdout.saved
+		stdout = c.stdout.close()
+	}
+	if c.stderr != nil {
+		os.Stderr = c.stderr.saved
+		stderr = c.stderr.close()
+	}
+	return
+}
+
+func captureFile(f *os.File) *capturedFile {
+	r, w, err := os.Pipe()
+	if err != nil {
+		return nil
+	}
+	c := &capturedFile{
+		saved: f,
+		w:     w,
+		buf:   new(bytes.Buffer),
+		done:  make(chan struct{}),
+	}
+	// The pipe must be drained as it's written, or a chatty spec would block
+	// once its buffer filled
+	go func() {
+		io.Copy(c.buf, r)
+		r.Close()
+		close(c.done)
+	}()
+	return c
+}
+
+func (c *capturedFile) close() string {
+	c.w.Close()
+	<-c.done
+	return c.buf.String()
+}
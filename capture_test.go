@@ -0,0 +1,79 @@
+package spec
+
+import (
+	"bytes"
+	"fmt"
+	"github.com/markchadwick/assert"
+	"os"
+	"strings"
+	"testing"
+)
+
+func TestCaptureNested(t *testing.T) {
+	stdout := os.Stdout
+
+	outer := startCapture()
+	fmt.Print("outer ")
+	inner := startCapture()
+	fmt.Print("inner")
+	fmt.Fprint(os.Stderr, "oops")
+	innerOut, innerErr := inner.stop()
+	fmt.Print("again")
+	outerOut, outerErr := outer.stop()
+
+	assert.That(t, os.Stdout).Equals(stdout)
+	assert.That(t, innerOut).Equals("inner")
+	assert.That(t, innerErr).Equals("oops")
+	assert.That(t, outerOut).Equals("outer again")
+	assert.That(t, outerErr).Equals("")
+}
+
+func TestCaptureBareContext(t *testing.T) {
+	child := (&C{}).It("bare", func(c *C) {})
+	assert.That(t, child).NotNil()
+	assert.That(t, child.capture).IsFalse()
+}
+
+func TestCaptureSpecs(t *testing.T) {
+	var parent, failing *suite
+	parent = Suite("Chatty suite", func(c *C) {
+		fmt.Println("setting up")
+		c.It("passes", func(c *C) {
+			fmt.Println("all good")
+		})
+		failing = c.It("fails", func(c *C) {
+			fmt.Println("about to fail")
+			fmt.Fprintln(os.Stderr, "failing now")
+			c.Failf("nope")
+		})
+	})
+
+	console := new(bytes.Buffer)
+	junit := new(bytes.Buffer)
+	r := Runner(parent)
+	r.Capture = true
+	r.Run(ConsoleTo(console, ConsoleOptions{Color: "never"}), JUnit(junit))
+
+	// The setup is only kept from the first time it ran
+	assert.That(t, parent.Stdout).Equals("setting up\n")
+	assert.That(t, r.Result().Children[0].Children[0].Stdout).Equals("all good\n")
+	assert.That(t, failing.Stdout).Equals("about to fail\n")
+	assert.That(t, failing.Stderr).Equals("failing now\n")
+
+	out := console.String()
+	assert.That(t, strings.Contains(out, "all good")).IsFalse()
+	assert.That(t, strings.Contains(out, "  Captured stdout:\n    about to fail\n")).
+		IsTrue()
+	assert.That(t, strings.Contains(out, "  Captured stderr:\n    failing now\n")).
+		IsTrue()
+
+	report := junit.String()
+	assert.That(t, strings.Contains(report, "<system-out>all good&#xA;</system-out>")).
+		IsTrue()
+	assert.That(t, strings.Contains(report, "<system-err>failing now&#xA;</system-err>")).
+		IsTrue()
+
+	fails := r.Result().Filter(func(r *Result) bool { return r.Status == Failed })
+	assert.That(t, fails).HasLen(1)
+	assert.That(t, fails[0].Stdout).Equals("about to fail\n")
+}
@@ -1,12 +1,13 @@
 package spec
 
 import (
-	"bufio"
 	"fmt"
 	"github.com/markchadwick/assert"
-	"os"
+	"reflect"
 	"runtime"
 	"strings"
+	"sync"
+	"time"
 )
 
 type onChild func(*suite)
@@ -15,10 +16,16 @@ type C struct {
 	suite   *suite
 	onChild onChild
 	errors  []*TestError
+	logs    []*LogEntry
+	helpers map[string]bool
+	lock    sync.Mutex
 }
 
 func (c *C) It(name string, test Test) *suite {
 	s := Suite(name, test)
+	if c.suite != nil {
+		s.capture = c.suite.capture
+	}
 	if c.onChild != nil {
 		c.onChild(s)
 	}
@@ -36,29 +43,115 @@ func (c *C) Failf(f string, args ...interface{}) *C {
 func (c *C) Skip(msg string, args ...interface{}) *C {
 	err := &TestError{
 		Err:  fmt.Errorf(msg, args...),
+		Kind: KindSkip,
 		skip: true,
 	}
-	err.inspect(1)
+	err.inspect(1, c)
 	panic(err)
 }
 
+// Fail unless the two values are deeply equal, showing how they differ.
+func (c *C) Equals(actual, expected interface{}) *C {
+	if reflect.DeepEqual(actual, expected) {
+		return c
+	}
+	diff, differences := valueDiff(expected, actual)
+	return c.failWith(&TestError{
+		Err:         fmt.Errorf("expected %#v, got %#v", expected, actual),
+		Kind:        KindAssertion,
+		Expected:    expected,
+		Actual:      actual,
+		Operator:    "==",
+		Diff:        diff,
+		Differences: differences,
+	}, 1)
+}
+
+// Fail if the two values are deeply equal.
+func (c *C) NotEquals(actual, unexpected interface{}) *C {
+	if !reflect.DeepEqual(actual, unexpected) {
+		return c
+	}
+	return c.failWith(&TestError{
+		Err:      fmt.Errorf("expected anything but %#v", unexpected),
+		Kind:     KindAssertion,
+		Expected: unexpected,
+		Actual:   actual,
+		Operator: "!=",
+	}, 1)
+}
+
 func (c *C) fail(err error, depth int) *C {
-	testError := &TestError{Err: err}
-	testError.inspect(depth + 1)
+	return c.failWith(&TestError{Err: err, Kind: KindFail}, depth+1)
+}
+
+func (c *C) failWith(testError *TestError, depth int) *C {
+	testError.inspect(depth+1, c)
 
 	c.errors = append(c.errors, testError)
 	return c
 }
 
+// ----------------------------------------------------------------------------
+// Logging
+// ----------------------------------------------------------------------------
+
+// A message logged by a spec, and where it was logged from.
+type LogEntry struct {
+	Time    time.Time `json:"time"`
+	File    string    `json:"file,omitempty"`
+	Line    int       `json:"line,omitempty"`
+	Message string    `json:"message"`
+}
+
+// The entry as it's shown beneath a spec: its time, base file name and line,
+// then the message with any further lines indented.
+func (l *LogEntry) String() string {
+	msg := strings.Replace(l.Message, "\n", "\n    ", -1)
+	return fmt.Sprintf("%s %s:%d: %s",
+		l.Time.Format("15:04:05.000"), shortFile(l.File), l.Line, msg)
+}
+
+// Log the arguments, formatted as by fmt.Println, against the running spec.
+// Like testing.T's Log, entries are kept quiet unless the spec fails or -spec.v
+// is set.
+func (c *C) Log(args ...interface{}) {
+	c.log(strings.TrimSuffix(fmt.Sprintln(args...), "\n"), 1)
+}
+
+// Log a message, formatted as by fmt.Printf, against the running spec.
+func (c *C) Logf(f string, args ...interface{}) {
+	c.log(strings.TrimSuffix(fmt.Sprintf(f, args...), "\n"), 1)
+}
+
+func (c *C) log(msg string, depth int) {
+	entry := &LogEntry{Time: time.Now(), Message: msg}
+	if frame := c.caller(stack(depth + 1)); frame != nil {
+		entry.File, entry.Line = frame.File, frame.Line
+	}
+	c.addLog(entry)
+}
+
+// Entries may come from loggers on other goroutines, so are added one at a
+// time.
+func (c *C) addLog(entry *LogEntry) {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+	c.logs = append(c.logs, entry)
+}
+
 // ----------------------------------------------------------------------------
 // "assert" integration
 // ----------------------------------------------------------------------------
 
+// Start an assertion on a value, failing the spec if any check on it doesn't
+// hold. Its failures carry the value as `Actual`, but no diff; compare with
+// `c.Equals` to see how two values differ.
 func (c *C) Assert(i interface{}) *assert.Assertion {
 	a := assert.Assert(i)
 	a.CheckAdded = func(check assert.Check) {
 		if err := a.CheckOne(check); err != nil {
-			c.fail(err, 3)
+			c.failWith(&TestError{Err: err, Kind: KindAssertion, Actual: i}, 3)
 		}
 	}
 	return a
@@ -68,43 +161,108 @@ func (c *C) Assert(i interface{}) *assert.Assertion {
 // Test Error
 // ----------------------------------------------------------------------------
 
+// What brought a spec to a halt, or marked it as failed.
+type ErrorKind string
+
+const (
+	// A check made with `c.Assert`, `c.Equals` or the like didn't hold
+	KindAssertion ErrorKind = "assertion"
+
+	// The spec failed itself with `c.Fail` or `c.Failf`
+	KindFail ErrorKind = "fail"
+
+	// The spec panicked
+	KindPanic ErrorKind = "panic"
+
+	// The spec ran for too long. Nothing in this package enforces a limit yet,
+	// but reporters treat these like panics.
+	KindTimeout ErrorKind = "timeout"
+
+	// Something run around the spec, rather than the spec itself, failed.
+	// Nothing in this package runs hooks yet, but reporters are ready for them.
+	KindHook ErrorKind = "hook"
+
+	// The spec was skipped, or is pending
+	KindSkip ErrorKind = "skip"
+)
+
+func (k ErrorKind) String() string {
+	return string(k)
+}
+
+// Whether a failure of this kind is an error in the spec, rather than a check
+// it made which didn't hold.
+func (k ErrorKind) isError() bool {
+	return k == KindPanic || k == KindTimeout || k == KindHook
+}
+
 type TestError struct {
 	Err  error
+	Kind ErrorKind
 	File string
 	Line int
-	skip bool
+
+	// The calls which led to the failure, innermost first. Helpers are
+	// included, though File and Line skip past them.
+	Stack []*StackFrame
+
+	// What a comparison expected, and what it was given instead, when the
+	// failure came from one. Operator is how they were compared, such as "==".
+	// Differences holds each part of the two which differs, and Diff the same,
+	// one per line.
+	//
+	// Only `c.Equals` and `c.NotEquals` fill in all of these, so only they
+	// produce diffs. Checks made with `c.Assert` keep their expected value to
+	// themselves, so its failures have just the Actual value asserted on.
+	Expected    interface{}
+	Actual      interface{}
+	Operator    string
+	Diff        string
+	Differences []*Difference
+
+	skip    bool
+	pending bool
 }
 
 func (t *TestError) Error() string {
 	return t.Err.Error()
 }
 
+// The error the spec failed with, so errors.Is and errors.As can look for a
+// particular one.
+func (t *TestError) Unwrap() error {
+	return t.Err
+}
+
 func (t *TestError) Source() (string, error) {
 	line, err := readLine(t.File, t.Line)
 	line = strings.TrimSpace(line)
 	return line, err
 }
 
+// The failing line, numbered, along with up to `before` lines ahead of it and
+// `after` lines following it.
+func (t *TestError) Context(before, after int) ([]*SourceLine, error) {
+	return sourceContext(t.File, t.Line, before, after)
+}
+
 // Try to determine what the failing line of code is. It must be in the call
-// stack when this is called.
-func (t *TestError) inspect(depth int) {
+// stack when this is called. Any functions the context has been told are
+// helpers are skipped over.
+func (t *TestError) inspect(depth int, c *C) {
+	t.Stack = stack(depth + 1)
+	if frame := c.caller(t.Stack); frame != nil {
+		t.File, t.Line = frame.File, frame.Line
+		return
+	}
 	_, t.File, t.Line, _ = runtime.Caller(depth + 1)
 }
 
 // Read a single line of a file.
 func readLine(fname string, lineNo int) (string, error) {
-	file, err := os.Open(fname)
-	if err != nil {
+	lines, err := sources.lines(fname)
+	if err != nil || lineNo < 1 || lineNo > len(lines) {
 		return "", err
 	}
-	defer file.Close()
-
-	scanner := bufio.NewScanner(file)
-	i := 1
-	for ; scanner.Scan(); i++ {
-		if i == lineNo {
-			return scanner.Text(), nil
-		}
-	}
-	return "", scanner.Err()
+	return lines[lineNo-1], nil
 }
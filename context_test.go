@@ -1,9 +1,15 @@
 package spec
 
 import (
+	"bytes"
+	"encoding/xml"
 	"errors"
+	"fmt"
 	"github.com/markchadwick/assert"
+	"runtime"
+	"strings"
 	"testing"
+	"time"
 )
 
 func TestFailCallStack(t *testing.T) {
@@ -67,3 +73,133 @@ func TestSkipPanic(t *testing.T) {
 	c := &C{}
 	c.Skip("what am I doing?")
 }
+
+// nextLine returns the line following its caller, for tests which check the
+// position recorded by whatever is called there.
+func nextLine() int {
+	_, _, line, _ := runtime.Caller(1)
+	return line + 1
+}
+
+func TestLog(t *testing.T) {
+	c := &C{}
+	line := nextLine()
+	c.Log("count", 3)
+	c.Logf("%s\n", "twice\nover")
+	assert.That(t, c.logs).HasLen(2)
+
+	entry := c.logs[0]
+	assert.That(t, entry.Message).Equals("count 3")
+	assert.That(t, strings.HasSuffix(entry.File, "context_test.go")).IsTrue()
+	assert.That(t, entry.Line).Equals(line)
+	assert.That(t, time.Since(entry.Time) < time.Second).IsTrue()
+
+	entry.Time = time.Date(2024, 1, 2, 3, 4, 5, 6000000, time.UTC)
+	assert.That(t, entry.String()).
+		Equals(fmt.Sprintf("03:04:05.006 context_test.go:%d: count 3", line))
+
+	c.logs[1].Time = entry.Time
+	assert.That(t, c.logs[1].String()).
+		Equals(fmt.Sprintf("03:04:05.006 context_test.go:%d: twice\n    over", line+1))
+}
+
+func TestLogReported(t *testing.T) {
+	var quietly, loudly string
+	logSuite := func() *runner {
+		return Runner(Suite("Logging suite", func(c *C) {
+			c.Log("setting up")
+			c.It("passes", func(c *C) {
+				quietly = fmt.Sprintf("context_test.go:%d: quietly\n", nextLine())
+				c.Log("quietly")
+			})
+			c.It("fails", func(c *C) {
+				loudly = fmt.Sprintf("context_test.go:%d: loudly\n", nextLine())
+				c.Log("loudly")
+				c.Failf("nope")
+			})
+		}))
+	}
+
+	quiet := new(bytes.Buffer)
+	junit := new(bytes.Buffer)
+	r := logSuite()
+	r.Run(ConsoleTo(quiet, ConsoleOptions{Color: "never"}), JUnit(junit))
+
+	// Logs from replays of the parent are dropped
+	assert.That(t, r.Result().Children[0].Logs).HasLen(1)
+
+	out := quiet.String()
+	assert.That(t, strings.Contains(out, "quietly")).IsFalse()
+	assert.That(t, strings.Contains(out, "  Log:\n    ")).IsTrue()
+	assert.That(t, strings.Contains(out, loudly)).IsTrue()
+
+	report := new(testsuites)
+	assert.That(t, xml.Unmarshal(junit.Bytes(), report)).IsNil()
+	passes := report.Suites[0].Cases[1]
+	assert.That(t, strings.HasSuffix(passes.SystemOut, quietly)).
+		IsTrue()
+
+	verbose := new(bytes.Buffer)
+	logSuite().Run(ConsoleTo(verbose, ConsoleOptions{Color: "never", Verbose: true}))
+	assert.That(t, strings.Contains(verbose.String(), quietly)).
+		IsTrue()
+}
+
+var errSentinel = errors.New("sentinel")
+
+func TestErrorKinds(t *testing.T) {
+	r := Runner(Suite("Kinds suite", func(c *C) {
+		c.It("fails", func(c *C) {
+			c.Fail(fmt.Errorf("wrapped: %w", errSentinel))
+		})
+		c.It("asserts", func(c *C) {
+			c.Equals(1, 2)
+		})
+		c.It("panics", func(c *C) {
+			var m map[string]int
+			m["boom"]++
+		})
+		c.It("panics with a value", func(c *C) {
+			panic("boom")
+		})
+	}))
+	junit := new(bytes.Buffer)
+	console := new(bytes.Buffer)
+	r.Run(JUnit(junit), ConsoleTo(console, ConsoleOptions{Color: "never"}))
+
+	failed := r.Result().Filter(func(r *Result) bool { return r.Status == Failed })
+	assert.That(t, failed).HasLen(4)
+	assert.That(t, failed[0].Errors[0].Kind).Equals(KindFail)
+	assert.That(t, errors.Is(failed[0].Errors[0], errSentinel)).IsTrue()
+	assert.That(t, failed[1].Errors[0].Kind).Equals(KindAssertion)
+
+	panicked := failed[2].Errors[0]
+	assert.That(t, panicked.Kind).Equals(KindPanic)
+	assert.That(t, panicked.Source).Equals(`m["boom"]++`)
+	var runtimeErr runtime.Error
+	assert.That(t, errors.As(panicked, &runtimeErr)).IsTrue()
+
+	report := new(testsuites)
+	assert.That(t, xml.Unmarshal(junit.Bytes(), report)).IsNil()
+	assert.That(t, report.Failures).Equals(2)
+	assert.That(t, report.Errors).Equals(2)
+	cases := report.Suites[0].Cases
+	assert.That(t, cases[1].Failures[0].Type).Equals("fail")
+	assert.That(t, cases[2].Failures[0].Type).Equals("assertion")
+	assert.That(t, cases[3].Errors).HasLen(1)
+	assert.That(t, cases[3].Errors[0].Type).Equals("panic")
+	assert.That(t, cases[4].Errors[0].Type).Equals("panic")
+	assert.That(t, cases[4].Errors[0].Message).Equals("panic: boom")
+
+	out := console.String()
+	panics := strings.Index(out, "\nPanics (2)\n")
+	assertions := strings.Index(out, "\nFailed assertions (1)\n")
+	failures := strings.Index(out, "\nFailures (1)\n")
+	assert.That(t, panics >= 0 && panics < assertions && assertions < failures).IsTrue()
+}
+
+func TestErrorUnwrap(t *testing.T) {
+	err := &TestError{Err: fmt.Errorf("context: %w", errSentinel)}
+	assert.That(t, errors.Is(err, errSentinel)).IsTrue()
+	assert.That(t, errors.Unwrap(err)).Equals(err.Err)
+}
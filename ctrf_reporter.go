@@ -0,0 +1,151 @@
+package spec
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"runtime"
+	"strings"
+)
+
+// A reporter which writes a single Common Test Report Format (CTRF) document
+// once all suites have run.
+type CTRFReporter struct {
+	*resultBuilder
+
+	// Written as the report's environment. It starts with the platform the
+	// specs ran on, and anything else may be added.
+	Environment map[string]interface{}
+
+	w io.Writer
+}
+
+type ctrfReport struct {
+	ReportFormat string      `json:"reportFormat"`
+	SpecVersion  string      `json:"specVersion"`
+	Results      ctrfResults `json:"results"`
+}
+
+type ctrfResults struct {
+	Tool        ctrfTool               `json:"tool"`
+	Summary     ctrfSummary            `json:"summary"`
+	Tests       []*ctrfTest            `json:"tests"`
+	Environment map[string]interface{} `json:"environment,omitempty"`
+}
+
+type ctrfTool struct {
+	Name string `json:"name"`
+}
+
+type ctrfSummary struct {
+	Tests   int   `json:"tests"`
+	Passed  int   `json:"passed"`
+	Failed  int   `json:"failed"`
+	Pending int   `json:"pending"`
+	Skipped int   `json:"skipped"`
+	Other   int   `json:"other"`
+	Start   int64 `json:"start"`
+	Stop    int64 `json:"stop"`
+}
+
+type ctrfTest struct {
+	Name     string `json:"name"`
+	Status   string `json:"status"`
+	Duration int64  `json:"duration"`
+	Suite    string `json:"suite,omitempty"`
+	Message  string `json:"message,omitempty"`
+	Trace    string `json:"trace,omitempty"`
+	FilePath string `json:"filePath,omitempty"`
+	Line     int    `json:"line,omitempty"`
+}
+
+func CTRF(w io.Writer) *CTRFReporter {
+	hostname, _ := os.Hostname()
+	return &CTRFReporter{
+		resultBuilder: newResultBuilder(),
+		Environment: map[string]interface{}{
+			"osPlatform": runtime.GOOS,
+			"extra": map[string]string{
+				"arch":      runtime.GOARCH,
+				"goVersion": runtime.Version(),
+				"hostname":  hostname,
+			},
+		},
+		w: w,
+	}
+}
+
+func (c *CTRFReporter) Finish(errs []*SuiteFailure) {
+	c.resultBuilder.Finish(errs)
+
+	counts := c.root.Counts()
+	report := &ctrfReport{
+		ReportFormat: "CTRF",
+		SpecVersion:  "0.0.0",
+		Results: ctrfResults{
+			Tool: ctrfTool{Name: "spec"},
+			Summary: ctrfSummary{
+				Tests:   counts.Specs,
+				Passed:  counts.Passed,
+				Failed:  counts.Failed,
+				Pending: counts.Pending,
+				Skipped: counts.Skipped,
+				Start:   unixMillis(c.start),
+				Stop:    unixMillis(c.start.Add(c.root.Duration)),
+			},
+			Tests:       make([]*ctrfTest, 0, counts.Specs),
+			Environment: c.Environment,
+		},
+	}
+
+	c.root.Walk(func(path []string, r *Result) error {
+		report.Results.Tests = append(report.Results.Tests, ctrfNewTest(path, r))
+		return nil
+	})
+
+	enc := json.NewEncoder(c.w)
+	enc.SetIndent("", "  ")
+	enc.Encode(report)
+}
+
+func ctrfNewTest(path []string, r *Result) *ctrfTest {
+	test := &ctrfTest{
+		Name:     r.Name,
+		Duration: r.Duration.Nanoseconds() / 1e6,
+		Suite:    strings.Join(path[:len(path)-1], " > "),
+	}
+
+	switch r.Status {
+	case Passed:
+		test.Status = "passed"
+	case Failed:
+		test.Status = "failed"
+	case Skipped:
+		test.Status = "skipped"
+	case Pending:
+		test.Status = "pending"
+	default:
+		test.Status = "other"
+	}
+
+	if r.Skip != "" {
+		test.Message = r.Skip
+	}
+	if len(r.Errors) > 0 {
+		messages := make([]string, len(r.Errors))
+		traces := make([]string, len(r.Errors))
+		for i, err := range r.Errors {
+			messages[i] = err.Message
+			traces[i] = fmt.Sprintf("%s:%d", err.File, err.Line)
+			if err.Source != "" {
+				traces[i] += "\n    " + err.Source
+			}
+		}
+		test.Message = strings.Join(messages, "\n")
+		test.Trace = strings.Join(traces, "\n")
+		test.FilePath = r.Errors[0].File
+		test.Line = r.Errors[0].Line
+	}
+	return test
+}
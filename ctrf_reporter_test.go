@@ -0,0 +1,43 @@
+package spec
+
+import (
+	"bytes"
+	"encoding/json"
+	"github.com/markchadwick/assert"
+	"strings"
+	"testing"
+)
+
+func TestCTRFReport(t *testing.T) {
+	buf := new(bytes.Buffer)
+	runFixture(CTRF(buf))
+
+	report := new(ctrfReport)
+	assert.That(t, json.Unmarshal(buf.Bytes(), report)).IsNil()
+	assert.That(t, report.ReportFormat).Equals("CTRF")
+
+	results := report.Results
+	assert.That(t, results.Tool.Name).Equals("spec")
+	assert.That(t, results.Summary.Tests).Equals(6)
+	assert.That(t, results.Summary.Passed).Equals(3)
+	assert.That(t, results.Summary.Skipped).Equals(1)
+	assert.That(t, results.Summary.Pending).Equals(1)
+	assert.That(t, results.Summary.Failed).Equals(1)
+	assert.That(t, results.Summary.Stop >= results.Summary.Start).IsTrue()
+	assert.That(t, results.Environment["osPlatform"] != "").IsTrue()
+
+	assert.That(t, results.Tests).HasLen(6)
+	assert.That(t, results.Tests[0].Suite).Equals("")
+	assert.That(t, results.Tests[2].Message).Equals("later")
+	assert.That(t, results.Tests[3].Status).Equals("pending")
+
+	failed := results.Tests[5]
+	assert.That(t, failed.Name).Equals("fails")
+	assert.That(t, failed.Status).Equals("failed")
+	assert.That(t, failed.Suite).Equals("Fixture suite > nests")
+	assert.That(t, failed.Message).Equals("nope")
+	assert.That(t, failed.Line > 0).IsTrue()
+	assert.That(t, strings.HasSuffix(failed.FilePath, "/reporter_test.go")).
+		IsTrue()
+	assert.That(t, strings.HasSuffix(failed.Trace, `c.Failf("nope")`)).IsTrue()
+}
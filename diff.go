@@ -0,0 +1,518 @@
+package spec
+
+import (
+	"fmt"
+	"reflect"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// ----------------------------------------------------------------------------
+// Formatting values
+// ----------------------------------------------------------------------------
+
+// Write a value out as Go-like source on a single line, however large. Map
+// entries are sorted, and pointers, maps and slices already being printed are
+// shown as cycles rather than followed. Unlike fmt's %#v, it can read
+// unexported fields.
+func formatValue(v reflect.Value) string {
+	f := &valueFormatter{seen: make(map[reference]bool)}
+	f.format(v)
+	return f.buf.String()
+}
+
+type valueFormatter struct {
+	buf  strings.Builder
+	seen map[reference]bool
+}
+
+// What a pointer, map or slice refers to. Slices sharing an array are only the
+// same value if they're also the same length, as reflect.DeepEqual has it.
+type reference struct {
+	ptr uintptr
+	len int
+	typ reflect.Type
+}
+
+func referenceTo(v reflect.Value) reference {
+	ref := reference{ptr: v.Pointer(), typ: v.Type()}
+	if v.Kind() == reflect.Slice {
+		ref.len = v.Len()
+	}
+	return ref
+}
+
+// Mark a value as being printed until `done` is called, or return false if it
+// already is.
+func (f *valueFormatter) enter(v reflect.Value) (done func(), ok bool) {
+	ref := referenceTo(v)
+	if f.seen[ref] {
+		fmt.Fprintf(&f.buf, "<cycle to %s>", v.Type())
+		return nil, false
+	}
+	f.seen[ref] = true
+	return func() { delete(f.seen, ref) }, true
+}
+
+func (f *valueFormatter) format(v reflect.Value) {
+	if !v.IsValid() {
+		f.buf.WriteString("nil")
+		return
+	}
+
+	switch v.Kind() {
+	case reflect.Ptr:
+		if v.IsNil() {
+			f.buf.WriteString("nil")
+			return
+		}
+		done, ok := f.enter(v)
+		if !ok {
+			return
+		}
+		defer done()
+		f.buf.WriteString("&")
+		f.format(v.Elem())
+
+	case reflect.Interface:
+		f.format(v.Elem())
+
+	case reflect.Struct:
+		f.buf.WriteString(v.Type().String() + "{")
+		for i := 0; i < v.NumField(); i++ {
+			f.item(i)
+			f.buf.WriteString(v.Type().Field(i).Name + ": ")
+			f.format(v.Field(i))
+		}
+		f.buf.WriteString("}")
+
+	case reflect.Slice, reflect.Array:
+		if v.Kind() == reflect.Slice && v.IsNil() {
+			f.buf.WriteString("nil")
+			return
+		}
+		if v.Kind() == reflect.Slice {
+			done, ok := f.enter(v)
+			if !ok {
+				return
+			}
+			defer done()
+		}
+		f.buf.WriteString(v.Type().String() + "{")
+		for i := 0; i < v.Len(); i++ {
+			f.item(i)
+			f.format(v.Index(i))
+		}
+		f.buf.WriteString("}")
+
+	case reflect.Map:
+		if v.IsNil() {
+			f.buf.WriteString("nil")
+			return
+		}
+		done, ok := f.enter(v)
+		if !ok {
+			return
+		}
+		defer done()
+		f.buf.WriteString(v.Type().String() + "{")
+		for i, key := range sortKeys(v.MapKeys()) {
+			f.item(i)
+			f.format(key)
+			f.buf.WriteString(": ")
+			f.format(v.MapIndex(key))
+		}
+		f.buf.WriteString("}")
+
+	default:
+		f.buf.WriteString(formatScalar(v))
+	}
+}
+
+// Separate each item of a struct, slice or map from the one before it.
+func (f *valueFormatter) item(i int) {
+	if i > 0 {
+		f.buf.WriteString(", ")
+	}
+}
+
+// A value which doesn't contain any others. Its methods can't be called if it
+// came from an unexported field, so it's read by kind instead.
+func formatScalar(v reflect.Value) string {
+	switch v.Kind() {
+	case reflect.String:
+		return strconv.Quote(v.String())
+	case reflect.Bool:
+		return strconv.FormatBool(v.Bool())
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return strconv.FormatInt(v.Int(), 10)
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32,
+		reflect.Uint64, reflect.Uintptr:
+		return strconv.FormatUint(v.Uint(), 10)
+	case reflect.Float32, reflect.Float64:
+		return strconv.FormatFloat(v.Float(), 'g', -1, v.Type().Bits())
+	case reflect.Complex64, reflect.Complex128:
+		return strconv.FormatComplex(v.Complex(), 'g', -1, v.Type().Bits())
+	case reflect.Chan, reflect.Func, reflect.UnsafePointer:
+		if v.IsNil() {
+			return "nil"
+		}
+		return fmt.Sprintf("%s(%#x)", v.Type(), v.Pointer())
+	}
+	if v.CanInterface() {
+		return fmt.Sprintf("%#v", v.Interface())
+	}
+	return "<" + v.Type().String() + ">"
+}
+
+// The keys of a map, in the order they print.
+func sortKeys(keys []reflect.Value) []reflect.Value {
+	names := make([]string, len(keys))
+	for i, key := range keys {
+		names[i] = formatValue(key)
+	}
+	sort.Sort(&keySorter{keys, names})
+	return keys
+}
+
+type keySorter struct {
+	keys  []reflect.Value
+	names []string
+}
+
+func (s *keySorter) Len() int           { return len(s.keys) }
+func (s *keySorter) Less(i, j int) bool { return s.names[i] < s.names[j] }
+func (s *keySorter) Swap(i, j int) {
+	s.keys[i], s.keys[j] = s.keys[j], s.keys[i]
+	s.names[i], s.names[j] = s.names[j], s.names[i]
+}
+
+// ----------------------------------------------------------------------------
+// Line diffs
+// ----------------------------------------------------------------------------
+
+// How a line of a diff came about.
+const (
+	diffSame    = ' '
+	diffRemoved = '-'
+	diffAdded   = '+'
+)
+
+type diffLine struct {
+	op   byte
+	text string
+}
+
+// The most cells the table of common subsequences may have, which at 8 bytes
+// a cell is some 32MB. Past this, lines are no longer matched up.
+const maxDiffCells = 1 << 22
+
+// The shortest set of lines to remove from `a` and add from `b` to turn one
+// into the other, found from their longest common subsequence. Lines the two
+// start or end with are matched up first, and if what's left between them is
+// too large to compare line by line, all of it is removed and added whole.
+func lineDiff(a, b []string) []*diffLine {
+	diff := make([]*diffLine, 0, len(a)+len(b))
+
+	prefix := 0
+	for prefix < len(a) && prefix < len(b) && a[prefix] == b[prefix] {
+		diff = append(diff, &diffLine{diffSame, a[prefix]})
+		prefix++
+	}
+	suffix := 0
+	for suffix < len(a)-prefix && suffix < len(b)-prefix &&
+		a[len(a)-1-suffix] == b[len(b)-1-suffix] {
+		suffix++
+	}
+
+	diff = append(diff, lcsDiff(a[prefix:len(a)-suffix], b[prefix:len(b)-suffix])...)
+	for _, line := range a[len(a)-suffix:] {
+		diff = append(diff, &diffLine{diffSame, line})
+	}
+	return diff
+}
+
+func lcsDiff(a, b []string) []*diffLine {
+	diff := make([]*diffLine, 0, len(a)+len(b))
+	if (len(a)+1)*(len(b)+1) > maxDiffCells {
+		for _, line := range a {
+			diff = append(diff, &diffLine{diffRemoved, line})
+		}
+		for _, line := range b {
+			diff = append(diff, &diffLine{diffAdded, line})
+		}
+		return diff
+	}
+
+	// lcs[i][j] is the length of the longest common subsequence of a[i:] and
+	// b[j:]
+	lcs := make([][]int, len(a)+1)
+	for i := range lcs {
+		lcs[i] = make([]int, len(b)+1)
+	}
+	for i := len(a) - 1; i >= 0; i-- {
+		for j := len(b) - 1; j >= 0; j-- {
+			if a[i] == b[j] {
+				lcs[i][j] = lcs[i+1][j+1] + 1
+			} else if lcs[i+1][j] >= lcs[i][j+1] {
+				lcs[i][j] = lcs[i+1][j]
+			} else {
+				lcs[i][j] = lcs[i][j+1]
+			}
+		}
+	}
+
+	i, j := 0, 0
+	for i < len(a) && j < len(b) {
+		switch {
+		case a[i] == b[j]:
+			diff = append(diff, &diffLine{diffSame, a[i]})
+			i++
+			j++
+		case lcs[i+1][j] >= lcs[i][j+1]:
+			diff = append(diff, &diffLine{diffRemoved, a[i]})
+			i++
+		default:
+			diff = append(diff, &diffLine{diffAdded, b[j]})
+			j++
+		}
+	}
+	for ; i < len(a); i++ {
+		diff = append(diff, &diffLine{diffRemoved, a[i]})
+	}
+	for ; j < len(b); j++ {
+		diff = append(diff, &diffLine{diffAdded, b[j]})
+	}
+	return diff
+}
+
+// ----------------------------------------------------------------------------
+// Structural diffs
+// ----------------------------------------------------------------------------
+
+// A single place two values differ. The path leads from the top of the values
+// to the difference, such as `.Users[3].Email`, and is empty when the values
+// differ at the top.
+type Difference struct {
+	Path     string
+	Expected string
+	Actual   string
+
+	// When both sides are strings of more than one line, how they differ
+	// line by line, each line prefixed "-" for expected, "+" for actual or " "
+	// for both. Expected and Actual are left empty.
+	Lines []string
+}
+
+func (d *Difference) String() string {
+	if d.Lines != nil {
+		if d.Path == "" {
+			return strings.Join(d.Lines, "\n")
+		}
+		return d.Path + ":\n  " + strings.Join(d.Lines, "\n  ")
+	}
+	if d.Path == "" {
+		return d.Expected + " != " + d.Actual
+	}
+	return d.Path + ": " + d.Expected + " != " + d.Actual
+}
+
+// Compares values part by part, reporting only the parts which differ.
+type Differ struct {
+	// Whether to compare unexported struct fields, as reflect.DeepEqual does
+	Unexported bool
+}
+
+// The differ used by `c.Equals`. Set `Unexported` to false to leave unexported
+// fields out of its diffs.
+var DefaultDiffer = &Differ{Unexported: true}
+
+// The place-holder for one side of a difference which doesn't exist, such as a
+// map entry or slice element.
+const diffMissing = "<missing>"
+
+// Every difference between two values, in the order their paths would be
+// visited. Maps are visited in key order.
+func (d *Differ) Diff(expected, actual interface{}) []*Difference {
+	w := &diffWalker{opts: d, visited: make(map[diffVisit]bool)}
+	w.walk("", reflect.ValueOf(expected), reflect.ValueOf(actual))
+	return w.diffs
+}
+
+type diffWalker struct {
+	opts    *Differ
+	visited map[diffVisit]bool
+	diffs   []*Difference
+}
+
+// A pair of pointers, maps or slices being compared. If they're reached again
+// while still being compared, the values are cyclic, and whatever differs will
+// be found the first time around.
+type diffVisit struct {
+	expected reference
+	actual   reference
+}
+
+// Mark a pair of values as being compared until `done` is called, or return
+// false if they already are, or are one and the same.
+func (w *diffWalker) enter(expected, actual reflect.Value) (done func(), ok bool) {
+	visit := diffVisit{referenceTo(expected), referenceTo(actual)}
+	if w.visited[visit] || visit.expected == visit.actual {
+		return nil, false
+	}
+	w.visited[visit] = true
+	return func() { delete(w.visited, visit) }, true
+}
+
+func (w *diffWalker) walk(path string, expected, actual reflect.Value) {
+	if !expected.IsValid() || !actual.IsValid() {
+		if expected.IsValid() || actual.IsValid() {
+			w.mismatch(path, expected, actual)
+		}
+		return
+	}
+	if expected.Type() != actual.Type() {
+		w.add(path,
+			expected.Type().String()+"("+formatValue(expected)+")",
+			actual.Type().String()+"("+formatValue(actual)+")")
+		return
+	}
+
+	switch expected.Kind() {
+	case reflect.Ptr:
+		if expected.IsNil() || actual.IsNil() {
+			if expected.IsNil() != actual.IsNil() {
+				w.mismatch(path, expected, actual)
+			}
+			return
+		}
+		done, ok := w.enter(expected, actual)
+		if !ok {
+			return
+		}
+		defer done()
+		w.walk(path, expected.Elem(), actual.Elem())
+
+	case reflect.Interface:
+		if expected.IsNil() || actual.IsNil() {
+			if expected.IsNil() != actual.IsNil() {
+				w.mismatch(path, expected, actual)
+			}
+			return
+		}
+		w.walk(path, expected.Elem(), actual.Elem())
+
+	case reflect.Struct:
+		for i := 0; i < expected.NumField(); i++ {
+			field := expected.Type().Field(i)
+			if field.PkgPath != "" && !w.opts.Unexported {
+				continue
+			}
+			w.walk(path+"."+field.Name, expected.Field(i), actual.Field(i))
+		}
+
+	case reflect.Slice, reflect.Array:
+		if expected.Kind() == reflect.Slice {
+			if expected.IsNil() != actual.IsNil() {
+				w.mismatch(path, expected, actual)
+				return
+			}
+			done, ok := w.enter(expected, actual)
+			if !ok {
+				return
+			}
+			defer done()
+		}
+		for i := 0; i < expected.Len() || i < actual.Len(); i++ {
+			elem := fmt.Sprintf("%s[%d]", path, i)
+			switch {
+			case i >= actual.Len():
+				w.add(elem, formatValue(expected.Index(i)), diffMissing)
+			case i >= expected.Len():
+				w.add(elem, diffMissing, formatValue(actual.Index(i)))
+			default:
+				w.walk(elem, expected.Index(i), actual.Index(i))
+			}
+		}
+
+	case reflect.Map:
+		if expected.IsNil() != actual.IsNil() {
+			w.mismatch(path, expected, actual)
+			return
+		}
+		done, ok := w.enter(expected, actual)
+		if !ok {
+			return
+		}
+		defer done()
+		for _, key := range diffKeys(expected, actual) {
+			entry := path + "[" + formatValue(key) + "]"
+			e, a := expected.MapIndex(key), actual.MapIndex(key)
+			switch {
+			case !a.IsValid():
+				w.add(entry, formatValue(e), diffMissing)
+			case !e.IsValid():
+				w.add(entry, diffMissing, formatValue(a))
+			default:
+				w.walk(entry, e, a)
+			}
+		}
+
+	case reflect.String:
+		want, got := expected.String(), actual.String()
+		if want == got {
+			return
+		}
+		if !strings.Contains(want, "\n") && !strings.Contains(got, "\n") {
+			w.mismatch(path, expected, actual)
+			return
+		}
+		lines := make([]string, 0)
+		diff := lineDiff(strings.Split(want, "\n"), strings.Split(got, "\n"))
+		for _, line := range diff {
+			lines = append(lines, string(line.op)+" "+line.text)
+		}
+		w.diffs = append(w.diffs, &Difference{Path: path, Lines: lines})
+
+	default:
+		if formatScalar(expected) != formatScalar(actual) {
+			w.mismatch(path, expected, actual)
+		}
+	}
+}
+
+// Record a difference between the two values as a whole.
+func (w *diffWalker) mismatch(path string, expected, actual reflect.Value) {
+	w.add(path, formatValue(expected), formatValue(actual))
+}
+
+func (w *diffWalker) add(path, expected, actual string) {
+	w.diffs = append(w.diffs, &Difference{
+		Path:     path,
+		Expected: expected,
+		Actual:   actual,
+	})
+}
+
+// The keys of both maps, each once, in the order they print.
+func diffKeys(expected, actual reflect.Value) []reflect.Value {
+	keys := expected.MapKeys()
+	for _, key := range actual.MapKeys() {
+		if !expected.MapIndex(key).IsValid() {
+			keys = append(keys, key)
+		}
+	}
+	return sortKeys(keys)
+}
+
+// Every difference between two values, one per line, or nothing if they're
+// the same.
+func valueDiff(expected, actual interface{}) (string, []*Difference) {
+	diffs := DefaultDiffer.Diff(expected, actual)
+	lines := make([]string, len(diffs))
+	for i, diff := range diffs {
+		lines[i] = diff.String()
+	}
+	return strings.Join(lines, "\n"), diffs
+}
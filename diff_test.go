@@ -0,0 +1,211 @@
+package spec
+
+import (
+	"bytes"
+	"fmt"
+	"github.com/markchadwick/assert"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+type diffUser struct {
+	Name  string
+	Email string
+	tags  map[string]int
+	next  *diffUser
+}
+
+func TestFormatValue(t *testing.T) {
+	user := &diffUser{Name: "bob", tags: map[string]int{"b": 2, "a": 1}}
+	user.next = user
+
+	assert.That(t, formatValue(reflect.ValueOf(user))).Equals(
+		`&spec.diffUser{Name: "bob", Email: "", ` +
+			`tags: map[string]int{"a": 1, "b": 2}, next: <cycle to *spec.diffUser>}`)
+	assert.That(t, formatValue(reflect.ValueOf([]int{}))).Equals("[]int{}")
+	assert.That(t, formatValue(reflect.ValueOf(nil))).Equals("nil")
+}
+
+func TestLineDiff(t *testing.T) {
+	diff := lineDiff(
+		[]string{"a", "b", "c", "d"},
+		[]string{"a", "c", "x", "d"})
+
+	ops := ""
+	for _, line := range diff {
+		ops += string(line.op) + line.text
+	}
+	assert.That(t, ops).Equals(" a-b c+x d")
+}
+
+func TestLargeLineDiff(t *testing.T) {
+	a := make([]string, 5000)
+	b := make([]string, 5000)
+	for i := range a {
+		a[i], b[i] = fmt.Sprintf("a%d", i), fmt.Sprintf("b%d", i)
+	}
+	a[0], b[0] = "same", "same"
+
+	diff := lineDiff(a, b)
+	assert.That(t, diff).HasLen(9999)
+	assert.That(t, diff[0].op).Equals(byte(diffSame))
+	assert.That(t, diff[1].op).Equals(byte(diffRemoved))
+	assert.That(t, diff[5000].op).Equals(byte(diffAdded))
+}
+
+func TestEquals(t *testing.T) {
+	c := &C{}
+	c.Equals(1, 1)
+	assert.That(t, c.errors).HasLen(0)
+
+	line := nextLine()
+	c.Equals([]string{"a", "c"}, []string{"a", "b"})
+	assert.That(t, c.errors).HasLen(1)
+
+	err := c.errors[0]
+	assert.That(t, err.Expected).Equals([]string{"a", "b"})
+	assert.That(t, err.Actual).Equals([]string{"a", "c"})
+	assert.That(t, err.Operator).Equals("==")
+	assert.That(t, err.Diff).Equals(`[1]: "b" != "c"`)
+	assert.That(t, err.Differences).HasLen(1)
+	assert.That(t, err.Line).Equals(line)
+
+	c.NotEquals("same", "same")
+	assert.That(t, c.errors[1].Operator).Equals("!=")
+	assert.That(t, c.errors[1].Error()).Equals(`expected anything but "same"`)
+}
+
+func TestConsoleDiff(t *testing.T) {
+	buf := new(bytes.Buffer)
+	Runner(Suite("Diff suite", func(c *C) {
+		c.Equals(map[string]int{"a": 1, "b": 3}, map[string]int{"a": 1, "b": 2})
+	})).Run(ConsoleTo(buf, ConsoleOptions{Color: "never"}))
+
+	assert.That(t, strings.Contains(buf.String(), `
+      Differences (expected != actual):
+        ["b"]: 2 != 3
+`)).IsTrue()
+}
+
+type diffTeam struct {
+	Users []*diffUser
+	Notes string
+	Owner *diffUser
+}
+
+func diffStrings(diffs []*Difference) []string {
+	lines := make([]string, len(diffs))
+	for i, diff := range diffs {
+		lines[i] = diff.String()
+	}
+	return lines
+}
+
+func TestDifferPaths(t *testing.T) {
+	expected := &diffTeam{Users: []*diffUser{
+		{Name: "ann", Email: "a@x"},
+		{Name: "bob", Email: "b@x", tags: map[string]int{"admin": 1}},
+	}}
+	actual := &diffTeam{Users: []*diffUser{
+		{Name: "ann", Email: "a@x"},
+		{Name: "bob", Email: "c@x", tags: map[string]int{"ops": 1}},
+		{Name: "cat"},
+	}}
+
+	diffs := (&Differ{Unexported: true}).Diff(expected, actual)
+	assert.That(t, diffStrings(diffs)).Equals([]string{
+		`.Users[1].Email: "b@x" != "c@x"`,
+		`.Users[1].tags["admin"]: 1 != <missing>`,
+		`.Users[1].tags["ops"]: <missing> != 1`,
+		`.Users[2]: <missing> != &spec.diffUser{Name: "cat", Email: "", tags: nil, next: nil}`,
+	})
+
+	exported := (&Differ{}).Diff(expected, actual)
+	assert.That(t, exported).HasLen(2)
+}
+
+func TestDifferCycles(t *testing.T) {
+	a := &diffUser{Name: "a"}
+	a.next = a
+	b := &diffUser{Name: "b"}
+	b.next = b
+
+	diffs := DefaultDiffer.Diff(a, b)
+	assert.That(t, diffStrings(diffs)).Equals([]string{`.Name: "a" != "b"`})
+	assert.That(t, DefaultDiffer.Diff(a, a)).HasLen(0)
+}
+
+func TestDifferCyclicMaps(t *testing.T) {
+	a := map[string]interface{}{"name": "a"}
+	a["self"] = a
+	b := map[string]interface{}{"name": "b", "extra": []interface{}{nil}}
+	b["self"] = b
+	b["extra"].([]interface{})[0] = b["extra"]
+
+	diffs := DefaultDiffer.Diff(a, b)
+	assert.That(t, diffStrings(diffs)).Equals([]string{
+		`["extra"]: <missing> != []interface {}{<cycle to []interface {}>}`,
+		`["name"]: "a" != "b"`,
+	})
+	assert.That(t, formatValue(reflect.ValueOf(a))).Equals(
+		`map[string]interface {}{"name": "a", "self": <cycle to map[string]interface {}>}`)
+}
+
+func TestDifferMultilineStrings(t *testing.T) {
+	diffs := DefaultDiffer.Diff(
+		diffTeam{Notes: "one\ntwo\nthree"},
+		diffTeam{Notes: "one\n2\nthree"})
+
+	assert.That(t, diffs).HasLen(1)
+	assert.That(t, diffs[0].Lines).Equals([]string{"  one", "- two", "+ 2", "  three"})
+	assert.That(t, diffs[0].String()).Equals(".Notes:\n    one\n  - two\n  + 2\n    three")
+
+	diffs = DefaultDiffer.Diff("one\ntwo", "one\n2")
+	assert.That(t, diffs).HasLen(1)
+	assert.That(t, diffs[0].String()).Equals("  one\n- two\n+ 2")
+}
+
+func TestDifferTypes(t *testing.T) {
+	diffs := DefaultDiffer.Diff(interface{}(1), interface{}("1"))
+	assert.That(t, diffStrings(diffs)).Equals([]string{`int(1) != string("1")`})
+
+	diffs = DefaultDiffer.Diff([]int(nil), []int{})
+	assert.That(t, diffStrings(diffs)).Equals([]string{`nil != []int{}`})
+
+	diffs = DefaultDiffer.Diff(float32(0.1), float32(0.2))
+	assert.That(t, diffStrings(diffs)).Equals([]string{`0.1 != 0.2`})
+}
+
+func TestReportedDiff(t *testing.T) {
+	junit := new(bytes.Buffer)
+	tap := new(bytes.Buffer)
+	r := Runner(Suite("Reported diff", func(c *C) {
+		c.It("differs", func(c *C) {
+			c.Equals(diffTeam{Notes: "b"}, diffTeam{Notes: "a"})
+		})
+		c.It("asserts", func(c *C) {
+			c.Assert([]int{1}).HasLen(2)
+		})
+	}))
+	r.Run(JUnit(junit), TAP(tap))
+
+	failed := r.Result().Filter(func(r *Result) bool { return r.Status == Failed })
+	assert.That(t, failed[0].Errors[0].Diff).Equals(`.Notes: "a" != "b"`)
+	assert.That(t, failed[0].Errors[0].Expected).
+		Equals(`spec.diffTeam{Users: nil, Notes: "a", Owner: nil}`)
+	assert.That(t, failed[0].Errors[0].Actual).
+		Equals(`spec.diffTeam{Users: nil, Notes: "b", Owner: nil}`)
+	assert.That(t, failed[0].Errors[0].Operator).Equals("==")
+
+	// An assertion keeps its expected value to itself
+	asserted := failed[1].Errors[0]
+	assert.That(t, asserted.Expected).Equals("")
+	assert.That(t, asserted.Actual).Equals("[]int{1}")
+	assert.That(t, asserted.Operator).Equals("")
+
+	assert.That(t, strings.Contains(junit.String(), `.Notes: &#34;a&#34; != &#34;b&#34;`)).
+		IsTrue()
+	assert.That(t, strings.Contains(tap.String(),
+		"\n      diff: |-\n        .Notes: \"a\" != \"b\"\n")).IsTrue()
+}
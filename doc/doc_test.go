@@ -23,6 +23,9 @@ var _ = spec.Suite("An Array", func(c *spec.C) {
 	c.It("should sort itself", func(c *spec.C) {
 		c.Skip("I don't know how to do this yet")
 	})
+
+	// Specs without a body are pending
+	c.It("should reverse itself", nil)
 })
 
 var _ = spec.Suite("Failing suite", func(c *spec.C) {
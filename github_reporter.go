@@ -0,0 +1,205 @@
+package spec
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+)
+
+// A reporter which writes GitHub Actions workflow commands. Each failure is
+// annotated at the line it happened, and each top-level suite is written as a
+// collapsible group. When `SummaryPath` is set, a Markdown table of every spec
+// is appended to it once the run finishes.
+type GitHubReporter struct {
+	// Write a warning annotation for each skipped spec
+	WarnSkips bool
+
+	// The file to append a Markdown summary to. Defaults to the value of
+	// $GITHUB_STEP_SUMMARY.
+	SummaryPath string
+
+	w         io.Writer
+	workspace string
+	stack     []string
+	rows      []*githubRow
+	start     time.Time
+	err       error
+}
+
+type githubRow struct {
+	path     []string
+	status   Status
+	duration time.Duration
+}
+
+var (
+	githubData     = strings.NewReplacer("%", "%25", "\r", "%0D", "\n", "%0A")
+	githubProperty = strings.NewReplacer("%", "%25", "\r", "%0D", "\n", "%0A",
+		":", "%3A", ",", "%2C")
+)
+
+func GitHubActions(w io.Writer) *GitHubReporter {
+	return &GitHubReporter{
+		SummaryPath: os.Getenv("GITHUB_STEP_SUMMARY"),
+		w:           w,
+		workspace:   os.Getenv("GITHUB_WORKSPACE"),
+		stack:       make([]string, 0),
+	}
+}
+
+func (g *GitHubReporter) Start(s *suite) {
+	if len(g.stack) == 0 {
+		g.command("group", nil, s.Name)
+	}
+}
+
+func (g *GitHubReporter) Pass(s *suite) {
+	g.row(s, Passed)
+}
+
+func (g *GitHubReporter) Fail(s *suite, errs []*TestError) {
+	title := strings.Join(append(g.stack, s.Name), " > ")
+	for _, err := range errs {
+		g.command("error", []string{
+			"file", g.relative(err.File),
+			"line", fmt.Sprint(err.Line),
+			"title", title,
+		}, err.Error())
+	}
+	g.row(s, Failed)
+	g.endGroup()
+}
+
+func (g *GitHubReporter) Skip(s *suite, skip *TestError) {
+	status := Skipped
+	if skip.pending {
+		status = Pending
+	}
+	if g.WarnSkips {
+		title := strings.Join(append(g.stack, s.Name), " > ")
+		props := []string{"title", title}
+		if skip.File != "" {
+			props = []string{
+				"file", g.relative(skip.File),
+				"line", fmt.Sprint(skip.Line),
+				"title", title,
+			}
+		}
+		g.command("warning", props, skip.Error())
+	}
+	g.row(s, status)
+	g.endGroup()
+}
+
+func (g *GitHubReporter) Descend(s *suite) {
+	g.stack = append(g.stack, s.Name)
+}
+
+func (g *GitHubReporter) Ascend(*suite) {
+	g.stack = g.stack[0 : len(g.stack)-1]
+	g.endGroup()
+}
+
+// The first error encountered writing the summary, if any.
+func (g *GitHubReporter) Err() error {
+	return g.err
+}
+
+func (g *GitHubReporter) Begin() {
+	g.start = time.Now()
+	g.rows = make([]*githubRow, 0)
+	g.err = nil
+}
+
+func (g *GitHubReporter) Finish([]*SuiteFailure) {
+	if g.SummaryPath == "" {
+		return
+	}
+	f, err := os.OpenFile(g.SummaryPath, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0644)
+	if err != nil {
+		g.err = err
+		return
+	}
+	buf := new(bytes.Buffer)
+	g.summary(buf, time.Now().Sub(g.start))
+	if _, err = buf.WriteTo(f); err != nil {
+		g.err = err
+	}
+	if err = f.Close(); err != nil && g.err == nil {
+		g.err = err
+	}
+}
+
+// Write a Markdown table of every spec which ran.
+func (g *GitHubReporter) summary(w io.Writer, duration time.Duration) {
+	counts := make(map[Status]int)
+	for _, row := range g.rows {
+		counts[row.status]++
+	}
+
+	fmt.Fprintf(w, "### Spec results\n\n")
+	fmt.Fprintf(w, "**%d passed, %d failed, %d skipped** in %s\n\n",
+		counts[Passed], counts[Failed], counts[Skipped]+counts[Pending], duration)
+	fmt.Fprintf(w, "| | Spec | Duration |\n")
+	fmt.Fprintf(w, "|---|---|---|\n")
+
+	icons := map[Status]string{
+		Passed:  ":white_check_mark:",
+		Failed:  ":x:",
+		Skipped: ":fast_forward:",
+		Pending: ":hourglass:",
+	}
+	for _, row := range g.rows {
+		name := strings.Join(row.path, " > ")
+		name = strings.Replace(name, "|", `\|`, -1)
+		fmt.Fprintf(w, "| %s | %s | %s |\n", icons[row.status], name, row.duration)
+	}
+	fmt.Fprintln(w)
+}
+
+func (g *GitHubReporter) row(s *suite, status Status) {
+	path := make([]string, len(g.stack), len(g.stack)+1)
+	copy(path, g.stack)
+	g.rows = append(g.rows, &githubRow{
+		path:     append(path, s.Name),
+		status:   status,
+		duration: s.Stats.Duration,
+	})
+}
+
+// Close the group for a top-level suite once it, and all its children, are
+// done.
+func (g *GitHubReporter) endGroup() {
+	if len(g.stack) == 0 {
+		g.command("endgroup", nil, "")
+	}
+}
+
+// Write a workflow command with the given property names and values.
+func (g *GitHubReporter) command(name string, props []string, msg string) {
+	cmd := "::" + name
+	for i := 0; i+1 < len(props); i += 2 {
+		if i == 0 {
+			cmd += " "
+		} else {
+			cmd += ","
+		}
+		cmd += props[i] + "=" + githubProperty.Replace(props[i+1])
+	}
+	fmt.Fprintf(g.w, "%s::%s\n", cmd, githubData.Replace(msg))
+}
+
+// Annotations need paths relative to the root of the repository.
+func (g *GitHubReporter) relative(file string) string {
+	if g.workspace == "" {
+		return file
+	}
+	if rel, err := filepath.Rel(g.workspace, file); err == nil {
+		return filepath.ToSlash(rel)
+	}
+	return file
+}
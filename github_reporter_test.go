@@ -0,0 +1,77 @@
+package spec
+
+import (
+	"bytes"
+	"github.com/markchadwick/assert"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestGitHubCommands(t *testing.T) {
+	buf := new(bytes.Buffer)
+	reporter := GitHubActions(buf)
+	reporter.workspace = ""
+	reporter.SummaryPath = ""
+	reporter.WarnSkips = true
+	runFixture(reporter)
+
+	lines := strings.Split(strings.TrimSpace(buf.String()), "\n")
+	assert.That(t, lines).HasLen(5)
+	assert.That(t, lines[0]).Equals("::group::Fixture suite")
+	assert.That(t, strings.HasPrefix(lines[1], "::warning file=")).IsTrue()
+	assert.That(t, strings.HasSuffix(lines[1],
+		",title=Fixture suite > skips::later")).IsTrue()
+	assert.That(t, lines[2]).Equals("::warning title=Fixture suite > is pending::pending")
+	assert.That(t, strings.Contains(lines[3], "reporter_test.go,line=")).IsTrue()
+	assert.That(t, strings.HasSuffix(lines[3],
+		",title=Fixture suite > nests > fails::nope")).IsTrue()
+	assert.That(t, lines[4]).Equals("::endgroup::")
+}
+
+func TestGitHubEscaping(t *testing.T) {
+	buf := new(bytes.Buffer)
+	GitHubActions(buf).command("error", []string{"title", "a, b: c"}, "no, 100%\nnot")
+	assert.That(t, buf.String()).Equals("::error title=a%2C b%3A c::no, 100%25%0Anot\n")
+}
+
+func TestGitHubSummary(t *testing.T) {
+	reporter := GitHubActions(new(bytes.Buffer))
+	reporter.SummaryPath = ""
+	runFixture(reporter)
+
+	buf := new(bytes.Buffer)
+	reporter.summary(buf, 0)
+	lines := strings.Split(buf.String(), "\n")
+
+	assert.That(t, lines[2]).Equals("**3 passed, 1 failed, 2 skipped** in 0s")
+	assert.That(t, strings.HasPrefix(lines[6],
+		"| :white_check_mark: | Fixture suite |")).IsTrue()
+	assert.That(t, strings.HasPrefix(lines[9], "| :hourglass: | Fixture suite > is pending |")).
+		IsTrue()
+	assert.That(t, strings.HasPrefix(lines[11], "| :x: | Fixture suite > nests > fails |")).
+		IsTrue()
+}
+
+func TestGitHubSummaryFile(t *testing.T) {
+	reporter := GitHubActions(new(bytes.Buffer))
+	reporter.SummaryPath = filepath.Join(t.TempDir(), "summary.md")
+	runFixture(reporter)
+	assert.That(t, reporter.Err()).IsNil()
+
+	summary, err := os.ReadFile(reporter.SummaryPath)
+	assert.That(t, err).IsNil()
+	assert.That(t, strings.HasPrefix(string(summary), "### Spec results\n")).IsTrue()
+
+	reporter.SummaryPath = filepath.Join(t.TempDir(), "missing", "summary.md")
+	runFixture(reporter)
+	assert.That(t, reporter.Err()).NotNil()
+}
+
+func TestGitHubRelativePaths(t *testing.T) {
+	reporter := GitHubActions(nil)
+	reporter.workspace = "/home/runner/work/repo"
+	assert.That(t, reporter.relative("/home/runner/work/repo/pkg/a_test.go")).
+		Equals("pkg/a_test.go")
+}
@@ -0,0 +1,176 @@
+package spec
+
+import (
+	"html/template"
+	"io"
+)
+
+// A reporter which writes a single, self-contained HTML page once all suites
+// have run. Suites can be expanded and collapsed, and specs filtered by name
+// and status.
+type HTMLReporter struct {
+	*resultBuilder
+
+	// The title of the page
+	Title string
+
+	w io.Writer
+}
+
+// How many lines of source to show either side of a failing line.
+const htmlContext = 3
+
+var htmlTemplate = template.Must(template.New("report").Funcs(template.FuncMap{
+	"context":  htmlSourceContext,
+	"hasFails": func(r *Result) bool { return r.Counts().Failed > 0 },
+	"hasOutput": func(r *Result) bool {
+		return len(r.Logs) > 0 || r.Stdout != "" || r.Stderr != ""
+	},
+	"icon": func(s Status) template.HTML {
+		switch s {
+		case Passed:
+			return "&#10003;"
+		case Failed:
+			return "&#10007;"
+		case Pending:
+			return "&#8230;"
+		}
+		return "&#8631;"
+	},
+}).Parse(htmlReport))
+
+func HTML(w io.Writer) *HTMLReporter {
+	return &HTMLReporter{
+		resultBuilder: newResultBuilder(),
+		Title:         "Spec results",
+		w:             w,
+	}
+}
+
+func (h *HTMLReporter) Finish(errs []*SuiteFailure) {
+	h.resultBuilder.Finish(errs)
+	htmlTemplate.Execute(h.w, map[string]interface{}{
+		"Title":  h.Title,
+		"Result": h.root,
+		"Counts": h.root.Counts(),
+	})
+}
+
+// The lines surrounding a failure, or nothing if they cannot be read.
+func htmlSourceContext(err *ResultError) []*SourceLine {
+	if err.File == "" {
+		return nil
+	}
+	lines, e := sourceContext(err.File, err.Line, htmlContext, htmlContext)
+	if e != nil {
+		return nil
+	}
+	return lines
+}
+
+const htmlReport = `<!DOCTYPE html>
+<html>
+<head>
+<meta charset="utf-8">
+<title>{{.Title}}</title>
+<style>
+body { font-family: -apple-system, "Segoe UI", Helvetica, Arial, sans-serif; margin: 2em; color: #24292e; }
+header { margin-bottom: 1.5em; }
+.summary { font-size: 1.1em; }
+.controls label { margin-left: 1em; }
+ul.tree, ul.tree ul { list-style: none; padding-left: 1.5em; margin: 0; }
+ul.tree { padding-left: 0; }
+li.spec { margin: 0.2em 0; }
+li.hidden { display: none; }
+summary { cursor: pointer; }
+.leaf { padding-left: 1.1em; }
+.icon { display: inline-block; width: 1.2em; font-weight: bold; }
+.pass > * > .icon, .pass > details > summary > .icon { color: #28a745; }
+.fail > * > .icon, .fail > details > summary > .icon, .fail .name { color: #cb2431; }
+.skip .icon, .skip .name, .pending .icon, .pending .name { color: #b08800; }
+.duration, .reason { color: #6a737d; font-size: 0.9em; margin-left: 0.5em; }
+.error { margin: 0.5em 0 0.5em 1.5em; border-left: 3px solid #cb2431; padding-left: 1em; }
+.location { font-family: monospace; color: #6a737d; }
+.message { white-space: pre-wrap; font-family: monospace; margin: 0.5em 0; }
+pre.diff { margin: 0.5em 0; padding: 0.5em; background: #f6f8fa; font-size: 0.9em; white-space: pre-wrap; }
+table.source { border-collapse: collapse; font-family: monospace; font-size: 0.9em; background: #f6f8fa; }
+table.source td { padding: 0 0.5em; white-space: pre; }
+table.source td.number { color: #959da5; text-align: right; user-select: none; }
+table.source tr.failing { background: #ffeef0; }
+details.output { margin: 0.5em 0 0.5em 1.5em; }
+details.output summary { color: #6a737d; font-size: 0.9em; }
+details.output h4 { margin: 0.5em 0 0.2em; font-size: 0.9em; color: #6a737d; }
+details.output pre { margin: 0; padding: 0.5em; background: #f6f8fa; font-size: 0.9em; white-space: pre-wrap; }
+</style>
+</head>
+<body>
+<header>
+<h1>{{.Title}}</h1>
+<p class="summary">{{.Counts.Specs}} specs: {{.Counts.Passed}} passed, {{.Counts.Failed}} failed, {{.Counts.Skipped}} skipped, {{.Counts.Pending}} pending in {{.Result.Duration}}</p>
+<div class="controls">
+<input id="search" type="search" placeholder="Filter specs" autofocus>
+<label><input type="checkbox" data-status="pass" checked> Passed</label>
+<label><input type="checkbox" data-status="fail" checked> Failed</label>
+<label><input type="checkbox" data-status="skip" checked> Skipped</label>
+<label><input type="checkbox" data-status="pending" checked> Pending</label>
+</div>
+</header>
+<ul class="tree">
+{{range .Result.Children}}{{template "spec" .}}{{end}}
+</ul>
+<script>
+(function() {
+  var search = document.getElementById('search');
+  var boxes = document.querySelectorAll('input[data-status]');
+  var specs = Array.prototype.slice.call(document.querySelectorAll('li.spec')).reverse();
+
+  function filter() {
+    var q = search.value.toLowerCase();
+    var shown = {};
+    Array.prototype.forEach.call(boxes, function(box) {
+      shown[box.getAttribute('data-status')] = box.checked;
+    });
+    // Children come before their parents, so a parent is shown if any of its
+    // children are
+    specs.forEach(function(li) {
+      var match = shown[li.getAttribute('data-status')] &&
+        li.getAttribute('data-name').toLowerCase().indexOf(q) >= 0;
+      var child = li.querySelector('li.spec:not(.hidden)');
+      li.classList.toggle('hidden', !(match || child));
+    });
+  }
+
+  search.addEventListener('input', filter);
+  Array.prototype.forEach.call(boxes, function(box) {
+    box.addEventListener('change', filter);
+  });
+})();
+</script>
+</body>
+</html>
+{{define "line"}}<span class="icon">{{icon .Status}}</span> <span class="name">{{.Name}}</span>{{if .Skip}}<span class="reason">{{.Skip}}</span>{{end}}<span class="duration">{{.Duration}}</span>{{if .Children}}<span class="duration">(total {{.Total}}, setup replay {{.Replay}})</span>{{end}}{{end}}
+{{define "output"}}<details class="output">
+<summary>Output</summary>
+{{with .Logs}}<h4>Logs</h4>
+<pre class="logs">{{range .}}{{.}}
+{{end}}</pre>{{end}}
+{{with .Stdout}}<h4>Stdout</h4>
+<pre class="stdout">{{.}}</pre>{{end}}
+{{with .Stderr}}<h4>Stderr</h4>
+<pre class="stderr">{{.}}</pre>{{end}}
+</details>
+{{end}}
+{{define "spec"}}<li class="spec {{.Status}}" data-status="{{.Status}}" data-name="{{.Name}}">
+{{if or .Children .Errors (hasOutput .)}}<details{{if hasFails .}} open{{end}}>
+<summary>{{template "line" .}}</summary>
+{{range .Errors}}<div class="error">
+<div class="location">{{.File}}:{{.Line}}</div>
+{{with context .}}<table class="source">{{range .}}<tr{{if .Failing}} class="failing"{{end}}><td class="number">{{.Number}}</td><td>{{.Text}}</td></tr>{{end}}</table>{{else}}{{if .Source}}<table class="source"><tr class="failing"><td>{{.Source}}</td></tr></table>{{end}}{{end}}
+<div class="message">{{.Message}}</div>
+{{with .Diff}}<pre class="diff">{{.}}</pre>{{end}}
+</div>
+{{end}}{{if hasOutput .}}{{template "output" .}}{{end}}{{if .Children}}<ul>
+{{range .Children}}{{template "spec" .}}{{end}}</ul>{{end}}
+</details>{{else}}<div class="leaf">{{template "line" .}}</div>{{end}}
+</li>
+{{end}}`
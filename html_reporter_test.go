@@ -0,0 +1,67 @@
+package spec
+
+import (
+	"bytes"
+	"fmt"
+	"github.com/markchadwick/assert"
+	"regexp"
+	"strings"
+	"testing"
+)
+
+func TestHTMLReport(t *testing.T) {
+	buf := new(bytes.Buffer)
+	report := HTML(buf)
+	report.Title = "HTML <report>"
+	runFixture(report)
+
+	html := buf.String()
+	assert.That(t, strings.HasPrefix(html, "<!DOCTYPE html>")).IsTrue()
+	assert.That(t, strings.Contains(html, "<title>HTML &lt;report&gt;</title>")).IsTrue()
+	assert.That(t, strings.Contains(html, `data-status="fail" data-name="fails"`)).
+		IsTrue()
+	failing := regexp.MustCompile(
+		`<tr class="failing"><td class="number">\d+</td><td>\s*c.Failf\(&#34;nope&#34;\)</td></tr>`)
+	assert.That(t, failing.MatchString(html)).IsTrue()
+	assert.That(t, strings.Contains(html,
+		"6 specs: 3 passed, 1 failed, 1 skipped, 1 pending")).IsTrue()
+}
+
+func TestHTMLOutput(t *testing.T) {
+	buf := new(bytes.Buffer)
+	r := Runner(Suite("HTML output", func(c *C) {
+		c.It("talks", func(c *C) {
+			c.Log("<hello>")
+			fmt.Println("printed")
+		})
+		c.It("is quiet", func(c *C) {})
+	}))
+	r.Capture = true
+	r.Run(HTML(buf))
+
+	html := buf.String()
+	assert.That(t, strings.Count(html, `<details class="output">`)).Equals(1)
+	assert.That(t, strings.Contains(html, ": &lt;hello&gt;\n</pre>")).IsTrue()
+	assert.That(t, strings.Contains(html, `<pre class="stdout">printed
+</pre>`)).IsTrue()
+	assert.That(t, strings.Contains(html, `<pre class="stderr">`)).IsFalse()
+}
+
+func TestHTMLDiff(t *testing.T) {
+	buf := new(bytes.Buffer)
+	Runner(Suite("HTML diff", func(c *C) {
+		c.Equals([]string{"a", "<c>"}, []string{"a", "b"})
+	})).Run(HTML(buf))
+
+	html := buf.String()
+	assert.That(t, strings.Contains(html,
+		`<pre class="diff">[1]: &#34;b&#34; != &#34;&lt;c&gt;&#34;</pre>`)).IsTrue()
+}
+
+func TestHTMLSourceContext(t *testing.T) {
+	lines := htmlSourceContext(&ResultError{File: "html_reporter_test.go", Line: 2})
+	assert.That(t, lines).HasLen(5)
+	assert.That(t, lines[0].Number).Equals(1)
+	assert.That(t, lines[0].Text).Equals("package spec")
+	assert.That(t, lines[1].Failing).IsTrue()
+}
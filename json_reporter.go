@@ -0,0 +1,135 @@
+package spec
+
+import (
+	"encoding/json"
+	"io"
+	"time"
+)
+
+// The version of the schema written by the JSON reporter. It will only change
+// when an existing field is removed or changes meaning; new fields may be added
+// at any time.
+const JSONVersion = 1
+
+// A single line of output from the JSON reporter. Durations are in
+// nanoseconds. The "pass", "fail" and "skip" events carry anything the spec
+// logged. An "ascend" event carries the total time spent in a suite and its
+// children, and how much of it was spent running the suite's body again ahead
+// of each child.
+type JSONEvent struct {
+	Version  int            `json:"version"`
+	Event    string         `json:"event"`
+	Time     time.Time      `json:"time"`
+	Path     []string       `json:"path,omitempty"`
+	Duration time.Duration  `json:"duration,omitempty"`
+	Total    time.Duration  `json:"total,omitempty"`
+	Replay   time.Duration  `json:"replay,omitempty"`
+	Errors   []*ResultError `json:"errors,omitempty"`
+	Skip     *ResultError   `json:"skip,omitempty"`
+	Logs     []*LogEntry    `json:"logs,omitempty"`
+	Counts   *Counts        `json:"counts,omitempty"`
+}
+
+// A reporter which writes one JSON object per line for each event as it
+// happens.
+type JSONReporter struct {
+	enc    *json.Encoder
+	stack  []string
+	counts Counts
+	start  time.Time
+}
+
+func JSON(w io.Writer) *JSONReporter {
+	return &JSONReporter{
+		enc:   json.NewEncoder(w),
+		stack: make([]string, 0),
+	}
+}
+
+func (j *JSONReporter) Start(s *suite) {
+	j.counts.Specs++
+	j.write(&JSONEvent{Event: "start", Path: j.path(s)})
+}
+
+func (j *JSONReporter) Pass(s *suite) {
+	j.counts.Passed++
+	j.write(&JSONEvent{
+		Event:    "pass",
+		Path:     j.path(s),
+		Duration: s.Stats.Duration,
+		Logs:     s.Logs,
+	})
+}
+
+func (j *JSONReporter) Fail(s *suite, errs []*TestError) {
+	j.counts.Failed++
+	event := &JSONEvent{
+		Event:    "fail",
+		Path:     j.path(s),
+		Duration: s.Stats.Duration,
+		Errors:   make([]*ResultError, len(errs)),
+		Logs:     s.Logs,
+	}
+	for i, err := range errs {
+		event.Errors[i] = newResultError(err)
+	}
+	j.write(event)
+}
+
+func (j *JSONReporter) Skip(s *suite, skip *TestError) {
+	if skip.pending {
+		j.counts.Pending++
+	} else {
+		j.counts.Skipped++
+	}
+	j.write(&JSONEvent{
+		Event:    "skip",
+		Path:     j.path(s),
+		Duration: s.Stats.Duration,
+		Skip:     newResultError(skip),
+		Logs:     s.Logs,
+	})
+}
+
+func (j *JSONReporter) Descend(s *suite) {
+	j.stack = append(j.stack, s.Name)
+	j.write(&JSONEvent{Event: "descend", Path: j.stack})
+}
+
+func (j *JSONReporter) Ascend(s *suite) {
+	j.write(&JSONEvent{
+		Event:  "ascend",
+		Path:   j.stack,
+		Total:  s.Stats.Total,
+		Replay: s.Stats.Replay,
+	})
+	j.stack = j.stack[0 : len(j.stack)-1]
+}
+
+func (j *JSONReporter) Begin() {
+	j.start = time.Now()
+	j.counts = Counts{}
+	j.write(&JSONEvent{Event: "begin"})
+}
+
+func (j *JSONReporter) Finish([]*SuiteFailure) {
+	counts := j.counts
+	j.write(&JSONEvent{
+		Event:    "finish",
+		Duration: time.Now().Sub(j.start),
+		Counts:   &counts,
+	})
+}
+
+// The full path to a suite about to run beneath the current stack.
+func (j *JSONReporter) path(s *suite) []string {
+	path := make([]string, len(j.stack), len(j.stack)+1)
+	copy(path, j.stack)
+	return append(path, s.Name)
+}
+
+func (j *JSONReporter) write(event *JSONEvent) {
+	event.Version = JSONVersion
+	event.Time = time.Now()
+	j.enc.Encode(event)
+}
@@ -0,0 +1,60 @@
+package spec
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"github.com/markchadwick/assert"
+	"testing"
+)
+
+func TestJSONEvents(t *testing.T) {
+	buf := new(bytes.Buffer)
+	runFixture(JSON(buf))
+
+	events := make([]*JSONEvent, 0)
+	scanner := bufio.NewScanner(buf)
+	for scanner.Scan() {
+		event := new(JSONEvent)
+		assert.That(t, json.Unmarshal(scanner.Bytes(), event)).IsNil()
+		assert.That(t, event.Version).Equals(JSONVersion)
+		events = append(events, event)
+	}
+
+	kinds := make([]string, len(events))
+	for i, event := range events {
+		kinds[i] = event.Event
+	}
+	assert.That(t, kinds).Equals([]string{
+		"begin",
+		"start", "pass", "descend",
+		"start", "pass", "descend", "ascend",
+		"start", "skip",
+		"start", "skip",
+		"start", "pass", "descend",
+		"start", "fail",
+		"ascend",
+		"ascend",
+		"finish",
+	})
+
+	skip := events[9]
+	assert.That(t, skip.Path).Equals([]string{"Fixture suite", "skips"})
+	assert.That(t, skip.Skip.Message).Equals("later")
+
+	fail := events[16]
+	assert.That(t, fail.Path).Equals([]string{"Fixture suite", "nests", "fails"})
+	assert.That(t, fail.Errors).HasLen(1)
+	assert.That(t, fail.Errors[0].Message).Equals("nope")
+	assert.That(t, fail.Errors[0].Source).Equals(`c.Failf("nope")`)
+	assert.That(t, fail.Errors[0].Line > 0).IsTrue()
+
+	finish := events[19]
+	assert.That(t, *finish.Counts).Equals(Counts{
+		Specs:   6,
+		Passed:  3,
+		Failed:  1,
+		Skipped: 1,
+		Pending: 1,
+	})
+}
@@ -0,0 +1,465 @@
+package spec
+
+import (
+	"bytes"
+	"encoding/xml"
+	"fmt"
+	"io"
+	"os"
+	"regexp"
+	"strings"
+	"time"
+	"unicode"
+	"unicode/utf8"
+)
+
+// A reporter which writes JUnit XML as Jenkins and Surefire expect it. Each
+// top-level suite is written as a `<testsuite>`, and every spec beneath it,
+// including the suite itself, as a `<testcase>`.
+type JunitReporter struct {
+	// How test cases are named. Defaults to `JunitReadableNames`.
+	Naming JunitNaming
+
+	// Whether a streaming reporter also syncs its file to disk after every
+	// test case, so results survive the machine going down as well as the
+	// process. It costs an fsync for each spec, so is off by default.
+	Sync bool
+
+	w        io.Writer
+	stream   bool
+	stack    []string
+	enc      *xml.Encoder
+	hostname string
+	start    time.Time
+	suites   *testsuites
+	suite    *testsuite
+	current  *testcase
+	classes  map[string]string
+	names    map[string]int
+}
+
+// Chooses the classname and name of a test case from the names of the suites
+// above a spec and the spec's own name. Whatever names are chosen, the
+// reporter will make sure no two test cases share both.
+type JunitNaming func(path []string, name string) (classname, testname string)
+
+var (
+	invalidClass = regexp.MustCompile(`[^A-Za-z0-9]`)
+	invalidName  = regexp.MustCompile(`[^A-Za-z0-9 ]`)
+)
+
+// Keeps each spec's name as it was written. Classnames are built from the
+// words of each suite above a spec, so "returns 404 for /users/:id" becomes
+// "Returns404ForUsersId".
+func JunitReadableNames(path []string, name string) (string, string) {
+	if len(path) == 0 {
+		return junitIdentifier(name), name
+	}
+	classes := make([]string, len(path))
+	for i, p := range path {
+		classes[i] = junitIdentifier(p)
+	}
+	return strings.Join(classes, "."), name
+}
+
+// Names as they were before `JunitReadableNames`: title-cased with everything
+// but ASCII letters, numbers and spaces removed, and every classname starting
+// with "test".
+func JunitLegacyNames(path []string, name string) (string, string) {
+	classes := []string{"test"}
+	for _, p := range path {
+		classes = append(classes, legacyClassName(p))
+	}
+	return strings.Join(classes, "."), legacyName(name)
+}
+
+type testsuites struct {
+	XMLName  xml.Name     `xml:"testsuites"`
+	Tests    int          `xml:"tests,attr"`
+	Failures int          `xml:"failures,attr"`
+	Errors   int          `xml:"errors,attr"`
+	Skipped  int          `xml:"skipped,attr"`
+	Time     string       `xml:"time,attr"`
+	Suites   []*testsuite `xml:"testsuite"`
+}
+
+type testsuite struct {
+	Name      string      `xml:"name,attr"`
+	Tests     int         `xml:"tests,attr"`
+	Failures  int         `xml:"failures,attr"`
+	Errors    int         `xml:"errors,attr"`
+	Skipped   int         `xml:"skipped,attr"`
+	Time      string      `xml:"time,attr"`
+	Timestamp string      `xml:"timestamp,attr"`
+	Hostname  string      `xml:"hostname,attr"`
+	Cases     []*testcase `xml:"testcase"`
+	start     time.Time
+}
+
+func (t *testsuite) Add(c *testcase) {
+	t.Tests++
+	if len(c.Errors) > 0 {
+		t.Errors++
+	} else if len(c.Failures) > 0 {
+		t.Failures++
+	}
+	if c.Skipped != nil {
+		t.Skipped++
+	}
+	t.Cases = append(t.Cases, c)
+}
+
+type skipped struct {
+	Message string `xml:"message,attr"`
+}
+
+type failure struct {
+	Message string `xml:"message,attr"`
+	Type    string `xml:"type,attr"`
+	Body    string `xml:",chardata"`
+}
+
+type testcase struct {
+	Classname string     `xml:"classname,attr"`
+	Name      string     `xml:"name,attr"`
+	Time      string     `xml:"time,attr"`
+	Failures  []*failure `xml:"failure"`
+	Errors    []*failure `xml:"error"`
+	Skipped   *skipped   `xml:"skipped,omitempty"`
+	SystemOut string     `xml:"system-out,omitempty"`
+	SystemErr string     `xml:"system-err,omitempty"`
+}
+
+func (t *testcase) Fail(err *TestError) {
+	msg := err.Error()
+	body := fmt.Sprintf("%s:%d\n", err.File, err.Line)
+	if src, e := err.Source(); e == nil {
+		body += fmt.Sprintf("    %s\n", src)
+	}
+	body += "\n" + msg
+	if err.Diff != "" {
+		body += "\n\n" + err.Diff
+	}
+	if len(err.Stack) > 0 {
+		body += "\n\n" + formatStack(err.Stack)
+	}
+
+	f := &failure{
+		Message: strings.SplitN(msg, "\n", 2)[0],
+		Type:    err.Kind.String(),
+		Body:    body,
+	}
+	// Panics and the like are errors in the test rather than failures of it
+	if err.Kind.isError() {
+		t.Errors = append(t.Errors, f)
+	} else {
+		t.Failures = append(t.Failures, f)
+	}
+}
+
+func JUnit(w io.Writer) *JunitReporter {
+	enc := xml.NewEncoder(w)
+	enc.Indent("", "  ")
+
+	hostname, _ := os.Hostname()
+
+	return &JunitReporter{
+		Naming:   JunitReadableNames,
+		w:        w,
+		stack:    make([]string, 0),
+		enc:      enc,
+		hostname: hostname,
+		suites:   &testsuites{},
+	}
+}
+
+// Like `JUnit`, but each test case is written and flushed as soon as it
+// finishes, rather than all at once when the run is over. If the run never
+// finishes, whatever was written can be closed with `RepairJUnit`.
+//
+// As a suite's totals aren't known until it is over, and its element is opened
+// before any of its tests run, neither `<testsuites>` nor `<testsuite>` has
+// tests, failures, errors, skipped or time attributes when streamed. Readers
+// which want them must count the test cases themselves.
+func JUnitStream(w io.Writer) *JunitReporter {
+	j := JUnit(w)
+	j.stream = true
+	return j
+}
+
+func (j *JunitReporter) Start(s *suite) {
+	if len(j.stack) == 0 {
+		now := time.Now()
+		j.suite = &testsuite{
+			Name:      s.Name,
+			Timestamp: now.Format("2006-01-02T15:04:05"),
+			Hostname:  j.hostname,
+			Cases:     make([]*testcase, 0),
+			start:     now,
+		}
+		if j.stream {
+			j.enc.EncodeToken(xml.StartElement{
+				Name: xml.Name{Local: "testsuite"},
+				Attr: []xml.Attr{
+					{Name: xml.Name{Local: "name"}, Value: j.suite.Name},
+					{Name: xml.Name{Local: "timestamp"}, Value: j.suite.Timestamp},
+					{Name: xml.Name{Local: "hostname"}, Value: j.suite.Hostname},
+				},
+			})
+			j.flush()
+		}
+	}
+
+	classname, name := j.caseName(s.Name)
+	j.current = &testcase{
+		Classname: classname,
+		Name:      name,
+		Failures:  make([]*failure, 0),
+	}
+}
+
+func (j *JunitReporter) Pass(s *suite) {
+	j.add(s)
+}
+
+func (j *JunitReporter) Fail(s *suite, errs []*TestError) {
+	for _, err := range errs {
+		j.current.Fail(err)
+	}
+	j.add(s)
+	j.endSuite()
+}
+
+func (j *JunitReporter) Skip(s *suite, e *TestError) {
+	j.current.Skipped = &skipped{e.Error()}
+	j.add(s)
+	j.endSuite()
+}
+
+func (j *JunitReporter) Descend(s *suite) {
+	j.stack = append(j.stack, s.Name)
+}
+
+func (j *JunitReporter) Ascend(*suite) {
+	j.stack = j.stack[0 : len(j.stack)-1]
+	j.endSuite()
+}
+
+func (j *JunitReporter) Begin() {
+	j.start = time.Now()
+	j.classes = make(map[string]string)
+	j.names = make(map[string]int)
+	j.suites = &testsuites{
+		Suites: make([]*testsuite, 0),
+	}
+
+	if j.stream {
+		fmt.Fprint(j.w, xml.Header)
+		j.enc.EncodeToken(xml.StartElement{Name: xml.Name{Local: "testsuites"}})
+		j.flush()
+	}
+}
+
+func (j *JunitReporter) Finish([]*SuiteFailure) {
+	j.suites.Time = junitTime(time.Now().Sub(j.start))
+	if j.stream {
+		j.enc.EncodeToken(xml.EndElement{Name: xml.Name{Local: "testsuites"}})
+		j.flush()
+		fmt.Fprintln(j.w)
+		return
+	}
+	fmt.Fprint(j.w, xml.Header)
+	j.enc.Encode(j.suites)
+}
+
+func (j *JunitReporter) add(s *suite) {
+	j.current.Time = junitTime(s.Stats.Duration)
+	j.current.SystemOut, j.current.SystemErr = junitSystemOut(s), s.Stderr
+	j.suite.Add(j.current)
+	if j.stream {
+		j.enc.Encode(j.current)
+		j.flush()
+	}
+	j.current = nil
+}
+
+// Push everything written so far out of any buffers, and on to disk if the
+// reporter syncs.
+func (j *JunitReporter) flush() {
+	j.enc.Flush()
+	if f, ok := j.w.(interface {
+		Flush() error
+	}); ok {
+		f.Flush()
+	}
+	if !j.Sync {
+		return
+	}
+	if f, ok := j.w.(interface {
+		Sync() error
+	}); ok {
+		f.Sync()
+	}
+}
+
+// Close the testsuite for a top-level suite once it, and all its children, are
+// done.
+func (j *JunitReporter) endSuite() {
+	if len(j.stack) != 0 {
+		return
+	}
+	j.suite.Time = junitTime(time.Now().Sub(j.suite.start))
+
+	j.suites.Tests += j.suite.Tests
+	j.suites.Failures += j.suite.Failures
+	j.suites.Errors += j.suite.Errors
+	j.suites.Skipped += j.suite.Skipped
+	j.suites.Suites = append(j.suites.Suites, j.suite)
+	if j.stream {
+		j.enc.EncodeToken(xml.EndElement{Name: xml.Name{Local: "testsuite"}})
+		j.flush()
+	}
+	j.suite = nil
+}
+
+// Name a test case for a spec under the current stack. Suites which would
+// share a classname have a number appended to all but the first, as do specs
+// which would share a name within a class.
+func (j *JunitReporter) caseName(s string) (string, string) {
+	classname, name := j.Naming(j.stack, s)
+
+	// Top-level suites have no path of their own, so are left to share
+	if len(j.stack) > 0 {
+		classname = j.uniqueClass(classname, strings.Join(j.stack, "\x00"))
+	}
+
+	key := classname + "\x00" + name
+	j.names[key]++
+	if n := j.names[key]; n > 1 {
+		name = fmt.Sprintf("%s (%d)", name, n)
+	}
+	return classname, name
+}
+
+// Different paths may be given the same classname. The first path to use a
+// classname keeps it, and others are numbered.
+func (j *JunitReporter) uniqueClass(classname, path string) string {
+	unique := classname
+	for i := 2; ; i++ {
+		if class, ok := j.classes[unique]; !ok || class == path {
+			j.classes[unique] = path
+			return unique
+		}
+		unique = fmt.Sprintf("%s_%d", classname, i)
+	}
+}
+
+func legacyClassName(s string) string {
+	s = strings.Title(s)
+	return invalidClass.ReplaceAllString(s, "")
+}
+
+func legacyName(s string) string {
+	s = strings.Title(s)
+	return invalidName.ReplaceAllString(s, "")
+}
+
+// A valid Java identifier made of the words in a name, each starting with a
+// capital letter. Identifiers may not start with a number, so one which would
+// is prefixed with an underscore.
+func junitIdentifier(s string) string {
+	words := strings.FieldsFunc(s, func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsDigit(r)
+	})
+	for i, word := range words {
+		r, size := utf8.DecodeRuneInString(word)
+		words[i] = string(unicode.ToUpper(r)) + word[size:]
+	}
+
+	id := strings.Join(words, "")
+	if r, _ := utf8.DecodeRuneInString(id); id == "" || unicode.IsDigit(r) {
+		id = "_" + id
+	}
+	return id
+}
+
+// Close a JUnit document which was cut short, such as one written by
+// `JUnitStream` when the process died. Anything after the last complete test
+// case is dropped, and every element still open is closed.
+func RepairJUnit(r io.Reader, w io.Writer) error {
+	data, err := io.ReadAll(r)
+	if err != nil {
+		return err
+	}
+
+	dec := xml.NewDecoder(bytes.NewReader(data))
+	stack := make([]string, 0)
+	safe := int64(0)
+	safeStack := make([]string, 0)
+	opened := false
+	for {
+		tok, err := dec.RawToken()
+		if err != nil {
+			break
+		}
+		switch t := tok.(type) {
+		case xml.StartElement:
+			stack = append(stack, t.Name.Local)
+			opened = true
+		case xml.EndElement:
+			if len(stack) == 0 {
+				break
+			}
+			stack = stack[0 : len(stack)-1]
+		}
+
+		// Only cut between test cases, never inside of one
+		if len(stack) <= 2 {
+			safe = dec.InputOffset()
+			safeStack = append(safeStack[0:0], stack...)
+		}
+	}
+
+	if !opened {
+		_, err = fmt.Fprintf(w, "%s<testsuites></testsuites>\n", xml.Header)
+		return err
+	}
+
+	if _, err = w.Write(bytes.TrimRight(data[:safe], " \t\r\n")); err != nil {
+		return err
+	}
+	for i := len(safeStack) - 1; i >= 0; i-- {
+		if _, err = fmt.Fprintf(w, "\n</%s>", safeStack[i]); err != nil {
+			return err
+		}
+	}
+	_, err = fmt.Fprintln(w)
+	return err
+}
+
+// Repair a JUnit file in place. See `RepairJUnit`.
+func RepairJUnitFile(path string) error {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return err
+	}
+	buf := new(bytes.Buffer)
+	if err = RepairJUnit(bytes.NewReader(data), buf); err != nil {
+		return err
+	}
+	return os.WriteFile(path, buf.Bytes(), 0644)
+}
+
+// A spec's logs, one per line, followed by whatever it wrote to stdout.
+func junitSystemOut(s *suite) string {
+	out := ""
+	for _, entry := range s.Logs {
+		out += entry.String() + "\n"
+	}
+	return out + s.Stdout
+}
+
+// Seconds, as JUnit expects them.
+func junitTime(d time.Duration) string {
+	return fmt.Sprintf("%.3f", d.Seconds())
+}
@@ -2,8 +2,11 @@ package spec
 
 import (
 	"bytes"
-	"log"
+	"encoding/xml"
+	"fmt"
+	"github.com/markchadwick/assert"
 	"os"
+	"strings"
 	"testing"
 )
 
@@ -12,27 +15,29 @@ var emptySuite = Suite("Empty JUnit Suite", func(c *C) {})
 var jUnitSpec = Suite("JUnit spec output", func(c *C) {
 
 	c.It("should sanitize class names", func(c *C) {
-		junit := JUnit(nil)
+		className := func(suite string) string {
+			class, _ := JunitReadableNames([]string{suite}, "spec")
+			return class
+		}
 
 		c.It("valid class names", func(c *C) {
-			c.Assert(junit.className("ClassName")).Equals("ClassName")
+			c.Assert(className("ClassName")).Equals("ClassName")
 		})
 
 		c.It("names with spaces", func(c *C) {
-			c.Assert(junit.className("Class Name")).Equals("ClassName")
+			c.Assert(className("Class Name")).Equals("ClassName")
 		})
 
 		c.It("names with numbers", func(c *C) {
-			c.Assert(junit.className("Class9 Name")).Equals("Class9Name")
+			c.Assert(className("Class9 Name")).Equals("Class9Name")
 		})
 
 		c.It("names starting with numbers", func(c *C) {
-			c.Skip("figure out the expr")
-			c.Assert(junit.className("6ClassName")).Equals("ClassName")
+			c.Assert(className("6ClassName")).Equals("_6ClassName")
 		})
 
 		c.It("names with symbols", func(c *C) {
-			c.Assert(junit.className(" Class! -Name_  ")).Equals("ClassName")
+			c.Assert(className(" Class! -Name_  ")).Equals("ClassName")
 		})
 	})
 
@@ -40,7 +45,7 @@ var jUnitSpec = Suite("JUnit spec output", func(c *C) {
 		buf := new(bytes.Buffer)
 		junit := JUnit(buf)
 
-		log.Printf("Junit: %v", junit)
+		c.Logf("Junit: %v", junit)
 	})
 
 	c.It("fails", func(c *C) {
@@ -52,3 +57,163 @@ func TestJunit(t *testing.T) {
 	t.Skip("Visual inspection time")
 	Runner(emptySuite, jUnitSpec).Run(JUnit(os.Stdout))
 }
+
+func TestJunitSchema(t *testing.T) {
+	var line int
+	buf := new(bytes.Buffer)
+	Runner(Suite("First", func(c *C) {
+		c.It("passes", func(c *C) {})
+		c.It("fails", func(c *C) {
+			line = nextLine()
+			c.Failf("nope\nat all")
+		})
+	}), Suite("Second", func(c *C) {
+		c.Skip("later")
+	})).Run(JUnit(buf))
+
+	assert.That(t, strings.HasPrefix(buf.String(), xml.Header)).IsTrue()
+
+	out := new(testsuites)
+	assert.That(t, xml.Unmarshal(buf.Bytes(), out)).IsNil()
+	assert.That(t, out.Tests).Equals(4)
+	assert.That(t, out.Failures).Equals(1)
+	assert.That(t, out.Skipped).Equals(1)
+	assert.That(t, out.Suites).HasLen(2)
+
+	first := out.Suites[0]
+	assert.That(t, first.Name).Equals("First")
+	assert.That(t, first.Tests).Equals(3)
+	assert.That(t, first.Failures).Equals(1)
+	assert.That(t, first.Timestamp != "").IsTrue()
+	assert.That(t, first.Cases[1].Time).Equals("0.000")
+
+	failure := first.Cases[2].Failures[0]
+	assert.That(t, failure.Message).Equals("nope")
+	assert.That(t, failure.Type).Equals("fail")
+	assert.That(t, strings.Contains(failure.Body, fmt.Sprintf(
+		"junit_reporter_test.go:%d\n    c.Failf(\"nope\\nat all\")\n\nnope\nat all", line))).
+		IsTrue()
+
+	second := out.Suites[1]
+	assert.That(t, second.Skipped).Equals(1)
+	assert.That(t, second.Cases[0].Skipped.Message).Equals("later")
+}
+
+func TestJunitReadableNames(t *testing.T) {
+	class, name := JunitReadableNames([]string{"Users API", "GET"},
+		"returns 404 for /users/:id")
+	assert.That(t, class).Equals("UsersAPI.GET")
+	assert.That(t, name).Equals("returns 404 for /users/:id")
+
+	class, _ = JunitReadableNames(nil, "2nd café")
+	assert.That(t, class).Equals("_2ndCafé")
+
+	for suite, want := range map[string]string{
+		"ClassName":        "ClassName",
+		"Class Name":       "ClassName",
+		"Class9 Name":      "Class9Name",
+		"6ClassName":       "_6ClassName",
+		" Class! -Name_  ": "ClassName",
+	} {
+		class, _ = JunitReadableNames([]string{suite}, "spec")
+		assert.That(t, class).Equals(want)
+	}
+}
+
+func TestJunitLegacyNames(t *testing.T) {
+	class, name := JunitLegacyNames([]string{"a suite"}, "does things!")
+	assert.That(t, class).Equals("test.ASuite")
+	assert.That(t, name).Equals("Does Things")
+
+	// Exactly as before, even where that isn't a valid identifier
+	class, _ = JunitLegacyNames([]string{"6 suites"}, "spec")
+	assert.That(t, class).Equals("test.6Suites")
+}
+
+func TestJunitUniqueNames(t *testing.T) {
+	junit := JUnit(nil)
+	junit.Begin()
+
+	junit.stack = []string{"a suite"}
+	class1, name1 := junit.caseName("spec")
+	_, name2 := junit.caseName("spec")
+
+	junit.stack = []string{"a-suite"}
+	class2, _ := junit.caseName("spec")
+
+	assert.That(t, class1).Equals("ASuite")
+	assert.That(t, name1).Equals("spec")
+	assert.That(t, name2).Equals("spec (2)")
+	assert.That(t, class2).Equals("ASuite_2")
+}
+
+func TestJunitStream(t *testing.T) {
+	buf := new(bytes.Buffer)
+	var partial string
+	Runner(Suite("Streamed", func(c *C) {
+		c.It("first", func(c *C) {})
+		c.It("second", func(c *C) {
+			partial = buf.String()
+		})
+	})).Run(JUnitStream(buf))
+
+	// The first case should be written before the second has finished
+	assert.That(t, strings.Contains(partial, `name="first"`)).IsTrue()
+	assert.That(t, strings.Contains(partial, `name="second"`)).IsFalse()
+
+	out := new(testsuites)
+	assert.That(t, xml.Unmarshal(buf.Bytes(), out)).IsNil()
+	assert.That(t, out.Suites).HasLen(1)
+	assert.That(t, out.Suites[0].Name).Equals("Streamed")
+	assert.That(t, out.Suites[0].Cases).HasLen(3)
+}
+
+type syncCounter struct {
+	bytes.Buffer
+	syncs int
+}
+
+func (s *syncCounter) Sync() error {
+	s.syncs++
+	return nil
+}
+
+func TestJunitStreamSync(t *testing.T) {
+	suite := Suite("Synced", func(c *C) {
+		c.It("passes", func(c *C) {})
+	})
+
+	w := new(syncCounter)
+	Runner(suite).Run(JUnitStream(w))
+	assert.That(t, w.syncs).Equals(0)
+
+	junit := JUnitStream(w)
+	junit.Sync = true
+	Runner(suite).Run(junit)
+	assert.That(t, w.syncs > 0).IsTrue()
+}
+
+func TestRepairJUnit(t *testing.T) {
+	truncated := xml.Header + `<testsuites>
+  <testsuite name="Cut short">
+    <testcase classname="CutShort" name="first" time="0.000"></testcase>
+    <testcase classname="CutShort" name="second" time="0.0`
+
+	buf := new(bytes.Buffer)
+	assert.That(t, RepairJUnit(strings.NewReader(truncated), buf)).IsNil()
+
+	out := new(testsuites)
+	assert.That(t, xml.Unmarshal(buf.Bytes(), out)).IsNil()
+	assert.That(t, out.Suites).HasLen(1)
+	assert.That(t, out.Suites[0].Cases).HasLen(1)
+	assert.That(t, out.Suites[0].Cases[0].Name).Equals("first")
+}
+
+func TestRepairEmptyJUnit(t *testing.T) {
+	buf := new(bytes.Buffer)
+	assert.That(t, RepairJUnit(strings.NewReader(""), buf)).IsNil()
+
+	out := new(testsuites)
+	assert.That(t, xml.Unmarshal(buf.Bytes(), out)).IsNil()
+	assert.That(t, out.Suites).HasLen(0)
+}
@@ -0,0 +1,138 @@
+package spec
+
+import (
+	"io"
+	"log"
+	"log/slog"
+	"os"
+	"strings"
+	"sync"
+	"time"
+)
+
+// The contexts of the specs currently running, innermost last. A parent is
+// still running while its children do, so only the last of these is the spec
+// output belongs to.
+var running = &runningSpecs{}
+
+type runningSpecs struct {
+	lock  sync.Mutex
+	stack []*C
+}
+
+func (r *runningSpecs) push(c *C) {
+	r.lock.Lock()
+	defer r.lock.Unlock()
+	r.stack = append(r.stack, c)
+}
+
+func (r *runningSpecs) pop() {
+	r.lock.Lock()
+	defer r.lock.Unlock()
+	r.stack = r.stack[0 : len(r.stack)-1]
+}
+
+// The innermost running spec, or nil if none is.
+func (r *runningSpecs) current() *C {
+	r.lock.Lock()
+	defer r.lock.Unlock()
+	if len(r.stack) == 0 {
+		return nil
+	}
+	return r.stack[len(r.stack)-1]
+}
+
+// A writer which logs everything written to it against the running spec. Each
+// write becomes a single entry, so output from loggers writing from more than
+// one goroutine is never mixed within an entry.
+type logRouter struct {
+	fallback io.Writer
+}
+
+// A writer which logs each write against whichever spec is running, as though
+// it had called `c.Log`. Writes made while no spec is running go to os.Stderr.
+//
+// It suits the standard library's loggers, which make a single write for each
+// message:
+//
+//	log.SetOutput(spec.LogWriter())
+func LogWriter() io.Writer {
+	return &logRouter{fallback: os.Stderr}
+}
+
+func (r *logRouter) Write(p []byte) (int, error) {
+	c := running.current()
+	if c == nil {
+		return r.fallback.Write(p)
+	}
+
+	entry := &LogEntry{
+		Time:    time.Now(),
+		Message: strings.TrimSuffix(string(p), "\n"),
+	}
+	if frame := logCaller(c); frame != nil {
+		entry.File, entry.Line = frame.File, frame.Line
+	}
+	c.addLog(entry)
+	return len(p), nil
+}
+
+// A slog handler which logs each record against whichever spec is running.
+// Records are formatted as by slog.TextHandler, though without a time, as
+// every log entry already has one. A nil opts is the same as the zero value.
+func SlogHandler(opts *slog.HandlerOptions) slog.Handler {
+	o := slog.HandlerOptions{}
+	if opts != nil {
+		o = *opts
+	}
+	replace := o.ReplaceAttr
+	o.ReplaceAttr = func(groups []string, a slog.Attr) slog.Attr {
+		if len(groups) == 0 && a.Key == slog.TimeKey {
+			return slog.Attr{}
+		}
+		if replace != nil {
+			return replace(groups, a)
+		}
+		return a
+	}
+	return slog.NewTextHandler(LogWriter(), &o)
+}
+
+// Send the output of the standard logger, and of slog's default logger, to the
+// running spec until the returned function is called to put them back. The
+// standard logger's flags are cleared meanwhile, as each entry carries its own
+// time and call site.
+//
+//	func TestSpecs(t *testing.T) {
+//		defer spec.RouteLogs()()
+//		spec.Run(t)
+//	}
+func RouteLogs() (restore func()) {
+	output, flags := log.Writer(), log.Flags()
+	logger := slog.Default()
+
+	// Setting slog's default also points the standard logger at it, so the
+	// standard logger must be set afterward, and put back afterward too.
+	slog.SetDefault(slog.New(SlogHandler(nil)))
+	log.SetOutput(LogWriter())
+	log.SetFlags(0)
+
+	return func() {
+		slog.SetDefault(logger)
+		log.SetOutput(output)
+		log.SetFlags(flags)
+	}
+}
+
+// Where a message written through a logger was logged from: the first caller
+// outside of the log and slog packages, and of the spec's helpers.
+func logCaller(c *C) *StackFrame {
+	calls := stack(2)
+	for i, frame := range calls {
+		if !strings.HasPrefix(frame.Function, "log.") &&
+			!strings.HasPrefix(frame.Function, "log/slog") {
+			return c.caller(calls[i:])
+		}
+	}
+	return nil
+}
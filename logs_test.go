@@ -0,0 +1,48 @@
+package spec
+
+import (
+	"bytes"
+	"github.com/markchadwick/assert"
+	"log"
+	"log/slog"
+	"strings"
+	"testing"
+)
+
+func TestRouteLogs(t *testing.T) {
+	var logLine, slogLine int
+	restore := RouteLogs()
+	r := Runner(Suite("Logging suite", func(c *C) {
+		logLine = nextLine()
+		log.Printf("from %s", "log")
+		c.It("child", func(c *C) {
+			slogLine = nextLine()
+			slog.Info("from slog", "n", 1)
+		})
+	}))
+	r.Run(nilReporter)
+	restore()
+
+	parent := r.Result().Children[0]
+	assert.That(t, parent.Logs).HasLen(1)
+	assert.That(t, parent.Logs[0].Message).Equals("from log")
+	assert.That(t, strings.HasSuffix(parent.Logs[0].File, "logs_test.go")).IsTrue()
+	assert.That(t, parent.Logs[0].Line).Equals(logLine)
+
+	child := parent.Children[0]
+	assert.That(t, child.Logs).HasLen(1)
+	assert.That(t, child.Logs[0].Message).Equals(`level=INFO msg="from slog" n=1`)
+	assert.That(t, child.Logs[0].Line).Equals(slogLine)
+
+	// The original loggers are back in place
+	assert.That(t, log.Flags()).Equals(log.LstdFlags)
+	_, routed := slog.Default().Handler().(*slog.TextHandler)
+	assert.That(t, routed).IsFalse()
+}
+
+func TestLogWriterWithoutSpec(t *testing.T) {
+	buf := new(bytes.Buffer)
+	w := &logRouter{fallback: buf}
+	w.Write([]byte("nobody's listening\n"))
+	assert.That(t, buf.String()).Equals("nobody's listening\n")
+}
@@ -1,11 +1,13 @@
 package spec
 
 import (
-	"encoding/xml"
+	"flag"
 	"fmt"
 	"github.com/mgutz/ansi"
 	"io"
-	"regexp"
+	"os"
+	"sort"
+	"strconv"
 	"strings"
 	"time"
 )
@@ -15,6 +17,16 @@ type SuiteFailure struct {
 	errors []*TestError
 }
 
+// The name of the suite which failed.
+func (f *SuiteFailure) Name() string {
+	return f.suite.Name
+}
+
+// Each of the errors the suite failed with.
+func (f *SuiteFailure) Errors() []*TestError {
+	return f.errors
+}
+
 type Reporter interface {
 	Start(*suite)
 	Pass(*suite)
@@ -26,12 +38,78 @@ type Reporter interface {
 	Finish([]*SuiteFailure)
 }
 
+// Milliseconds since the epoch, as reports such as Allure's and CTRF expect
+// times.
+func unixMillis(t time.Time) int64 {
+	return t.UnixNano() / 1e6
+}
+
 // ----------------------------------------------------------------------------
 // Console Reporter
 // ----------------------------------------------------------------------------
 
+var (
+	colorFlag = flag.String("spec.color", "auto",
+		"Color console output: auto, always or never")
+	formatFlag = flag.String("spec.format", "tree",
+		"Console output format: tree, dots, docs or failures")
+	slowFlag = flag.Duration("spec.slow", 200*time.Millisecond,
+		"Highlight specs which take longer than this")
+	slowestFlag = flag.Int("spec.slowest", 0,
+		"List this many of the slowest specs once the run is over")
+	verboseFlag = flag.Bool("spec.v", false,
+		"Show what every spec logs, not only those which fail")
+	contextFlag = flag.Int("spec.context", 0,
+		"Show this many lines of source either side of a failure")
+)
+
+type ConsoleOptions struct {
+	// Whether to color output: "auto", "always" or "never". Defaults to the
+	// value of -spec.color. When "auto", output is colored only when written to
+	// a terminal and $NO_COLOR is empty. Any other value is warned about and
+	// replaced with auto.
+	Color string
+
+	// How to show each spec as it runs. Defaults to the value of -spec.format.
+	//   tree:     every spec, nested beneath its parent, with its duration
+	//   dots:     a single character for every spec
+	//   docs:     every spec, nested beneath its parent, without timing
+	//   failures: nothing until the run is over
+	// Whichever is chosen, failures and a summary are written at the end. Any
+	// other format is warned about and replaced with tree.
+	Format string
+
+	// Specs which take longer than this are highlighted. Defaults to the value
+	// of -spec.slow, and a negative duration highlights nothing.
+	Slow time.Duration
+
+	// How many of the slowest specs to list once the run is over. Defaults to
+	// the value of -spec.slowest, and a negative number lists none.
+	Slowest int
+
+	// Show what each spec logs beneath it as it runs, rather than only for
+	// failures once the run is over. Also turned on by -spec.v.
+	Verbose bool
+
+	// How many lines of source to show either side of a failing line. Defaults
+	// to the value of -spec.context, and a negative number shows only the
+	// failing line.
+	Context int
+}
+
 type ConsoleReporter struct {
-	depth int
+	w       io.Writer
+	color   bool
+	rewrite bool
+	format  string
+	slow    time.Duration
+	slowest int
+	verbose bool
+	context int
+	depth   int
+	dots    int
+	stack   []string
+	timings []*specTiming
 
 	numSpec int
 	numPass int
@@ -41,227 +119,500 @@ type ConsoleReporter struct {
 	start time.Time
 }
 
+// How long a single spec took to run. Its stats are kept, rather than copied,
+// as a suite's total and replay times aren't known until its children are done.
+type specTiming struct {
+	path  []string
+	stats *stats
+}
+
 var (
 	fail     = "\xE2\x98\xA0"
-	iconPass = ansi.Color("\xE2\x9c\x93", "green")
-	iconFail = ansi.Color(fail, "white+b")
+	iconPass = "\xE2\x9c\x93"
 )
 
+// A console reporter writing to standard out.
 func Console() *ConsoleReporter {
-	return &ConsoleReporter{}
+	return ConsoleTo(os.Stdout, ConsoleOptions{})
+}
+
+// A console reporter writing to the given writer. The status of a running spec
+// is only shown, and rewritten in place once it finishes, when writing to a
+// terminal.
+func ConsoleTo(w io.Writer, opts ConsoleOptions) *ConsoleReporter {
+	tty := isTerminal(w)
+
+	color := opts.Color
+	if color == "" {
+		color = *colorFlag
+	}
+	switch color {
+	case "auto", "always", "never":
+	default:
+		fmt.Fprintf(w, "spec: unknown console color %q, using auto\n", color)
+		color = "auto"
+	}
+
+	format := opts.Format
+	if format == "" {
+		format = *formatFlag
+	}
+	switch format {
+	case "tree", "dots", "docs", "failures":
+	default:
+		fmt.Fprintf(w, "spec: unknown console format %q, using tree\n", format)
+		format = "tree"
+	}
+
+	slow := opts.Slow
+	if slow == 0 {
+		slow = *slowFlag
+	}
+	slowest := opts.Slowest
+	if slowest == 0 {
+		slowest = *slowestFlag
+	}
+	context := opts.Context
+	if context == 0 {
+		context = *contextFlag
+	}
+
+	c := &ConsoleReporter{
+		w:       w,
+		rewrite: tty && format == "tree",
+		format:  format,
+		slow:    slow,
+		slowest: slowest,
+		verbose: opts.Verbose || *verboseFlag,
+		context: context,
+		stack:   make([]string, 0),
+	}
+	switch color {
+	case "always":
+		c.color = true
+	case "never":
+		c.color = false
+	default:
+		c.color = tty && os.Getenv("NO_COLOR") == ""
+	}
+	return c
 }
 
 func (c *ConsoleReporter) Start(s *suite) {
 	c.numSpec++
-	c.status(" ", s.Name, nil)
+	if c.rewrite {
+		c.status(" ", s.Name, nil)
+	}
 }
 
 func (c *ConsoleReporter) Pass(s *suite) {
 	c.numPass++
-
-	c.status(iconPass, s.Name, &s.Stats.Duration)
-	fmt.Println()
+	c.time(s)
+
+	switch c.format {
+	case "dots":
+		c.dot(".", "green")
+	case "docs":
+		c.line(s.Name)
+		c.logs(s)
+	case "failures":
+	default:
+		c.status(c.colorize(iconPass, "green"), s.Name, &s.Stats.Duration)
+		fmt.Fprintln(c.w)
+		c.logs(s)
+	}
 }
 
 func (c *ConsoleReporter) Fail(s *suite, errs []*TestError) {
 	c.numFail++
-
-	name := ansi.Color(s.Name, "red")
-	c.status(iconFail, name, &s.Stats.Duration)
-	fmt.Println()
+	c.time(s)
+
+	name := c.colorize(s.Name, "red")
+	switch c.format {
+	case "dots":
+		c.dot("F", "red")
+	case "docs":
+		c.line(name + c.colorize(" (FAILED)", "red"))
+		c.logs(s)
+	case "failures":
+	default:
+		c.status(c.colorize(fail, "white+b"), name, &s.Stats.Duration)
+		fmt.Fprintln(c.w)
+		c.logs(s)
+	}
 }
 
 func (c *ConsoleReporter) Skip(s *suite, skip *TestError) {
 	c.numSkip++
+	c.time(s)
 
 	reason := skip.Error()
-	msg := fmt.Sprintf("%s %s", ansi.Color(s.Name, "yellow"), reason)
-	c.status(" ", msg, &s.Stats.Duration)
-	fmt.Println()
+	msg := fmt.Sprintf("%s %s", c.colorize(s.Name, "yellow"), reason)
+	switch c.format {
+	case "dots":
+		c.dot("*", "yellow")
+	case "docs":
+		c.line(fmt.Sprintf("%s (SKIPPED: %s)", c.colorize(s.Name, "yellow"), reason))
+		c.logs(s)
+	case "failures":
+	default:
+		c.status(" ", msg, &s.Stats.Duration)
+		fmt.Fprintln(c.w)
+		c.logs(s)
+	}
 }
 
-func (c *ConsoleReporter) Descend(*suite) {
+func (c *ConsoleReporter) Descend(s *suite) {
 	c.depth++
+	c.stack = append(c.stack, s.Name)
 }
 
 func (c *ConsoleReporter) Ascend(*suite) {
 	c.depth--
+	c.stack = c.stack[0 : len(c.stack)-1]
 }
 
 func (c *ConsoleReporter) Begin() {
 	c.start = time.Now()
-	fmt.Println()
+	c.dots = 0
+	c.timings = make([]*specTiming, 0)
+	fmt.Fprintln(c.w)
 }
 
 func (c *ConsoleReporter) Finish(errs []*SuiteFailure) {
 	duration := time.Now().Sub(c.start)
-	fmt.Printf("\n\n----------------------------------------------------\n")
-	fmt.Printf("%d PASSED %d FAILED %d SKIPPED\n", c.numPass, c.numFail, c.numSkip)
-
-	for _, err := range errs {
-		c.printSuiteFailure(err)
+	fmt.Fprintf(c.w, "\n\n----------------------------------------------------\n")
+	fmt.Fprintf(c.w, "%d PASSED %d FAILED %d SKIPPED\n", c.numPass, c.numFail, c.numSkip)
+
+	for _, group := range groupFailures(errs) {
+		fmt.Fprintf(c.w, "\n%s\n", c.colorize(
+			fmt.Sprintf("%s (%d)", group.title, len(group.failures)), "red+b"))
+		for _, err := range group.failures {
+			c.printSuiteFailure(err)
+		}
 	}
 
+	c.printSlowest(duration)
+
 	var status string
 	if len(errs) == 0 {
-		status = ansi.Color("OK", "green")
+		status = c.colorize("OK", "green")
 	} else {
-		status = ansi.Color("FAIL", "red")
+		status = c.colorize("FAIL", "red")
 	}
 
-	fmt.Printf("%s (%d specs in %s)\n", status, c.numSpec, duration)
+	fmt.Fprintf(c.w, "%s (%d specs in %s)\n", status, c.numSpec, duration)
 }
 
-func (c *ConsoleReporter) status(icon, msg string, duration *time.Duration) {
-	fmt.Print("\r")
+// Write a single line for a spec, indented beneath its parent.
+func (c *ConsoleReporter) line(msg string) {
 	c.pad()
+	fmt.Fprintln(c.w, msg)
+}
 
-	dur := ""
-	if duration != nil {
-		dur = duration.String()
+// When verbose, write what a spec logged beneath it.
+func (c *ConsoleReporter) logs(s *suite) {
+	if !c.verbose {
+		return
 	}
-
-	fmt.Printf("%s %-10s %s", icon, msg, ansi.Color(dur, "+h"))
+	c.depth++
+	for _, entry := range s.Logs {
+		for _, line := range strings.Split(entry.String(), "\n") {
+			c.line(c.colorize(line, "+h"))
+		}
+	}
+	c.depth--
 }
 
-func (c *ConsoleReporter) statusf(msg string, args ...interface{}) {
-	fmt.Print("\r")
-	c.pad()
-	fmt.Printf(msg, args...)
+// Write a single character for a spec, wrapping lines so they don't run on
+// forever.
+func (c *ConsoleReporter) dot(dot, color string) {
+	if c.dots > 0 && c.dots%80 == 0 {
+		fmt.Fprintln(c.w)
+	}
+	c.dots++
+	fmt.Fprint(c.w, c.colorize(dot, color))
 }
 
-func (c *ConsoleReporter) printSuiteFailure(err *SuiteFailure) {
-	fmt.Println()
-	fmt.Printf("  FAILURE in '%s'\n", err.suite.Name)
-
-	for _, err := range err.errors {
-		fmt.Printf("  %s %s:%d\n", ansi.Color(fail, "red+b"), err.File, err.Line)
-		if src, e := err.Source(); e == nil {
-			fmt.Printf("    %s\n", ansi.Color(src, "white+b"))
-		}
+func (c *ConsoleReporter) status(icon, msg string, duration *time.Duration) {
+	if c.rewrite {
+		fmt.Fprint(c.w, "\r")
+	}
+	c.pad()
 
-		for _, msg := range strings.Split(err.Error(), "\n") {
-			fmt.Printf("      %s\n", msg)
+	dur := ""
+	if duration != nil {
+		dur = c.colorize(duration.String(), "+h")
+		if c.isSlow(*duration) {
+			dur = c.colorize(duration.String()+" SLOW", "yellow+b")
 		}
-		fmt.Println()
 	}
-}
 
-func (c *ConsoleReporter) pad() int {
-	pad := "  "
-	for i := 0; i < c.depth; i++ {
-		fmt.Print(pad)
-	}
-	return c.depth * len(pad)
+	fmt.Fprintf(c.w, "%s %-10s %s", icon, msg, dur)
 }
 
-// ----------------------------------------------------------------------------
-// JUnit Reporter
-// ----------------------------------------------------------------------------
-
-type JunitReporter struct {
-	w            io.Writer
-	stack        []string
-	enc          *xml.Encoder
-	invalidName  *regexp.Regexp
-	invalidClass *regexp.Regexp
-	suite        *testsuite
-	current      *testcase
+func (c *ConsoleReporter) isSlow(d time.Duration) bool {
+	return c.slow > 0 && d > c.slow
 }
 
-type testsuite struct {
-	Tests int         `xml:"tests,attr"`
-	Cases []*testcase `xml:"testcase"`
+// Remember how long a spec took, for the list of the slowest
+func (c *ConsoleReporter) time(s *suite) {
+	path := make([]string, len(c.stack), len(c.stack)+1)
+	copy(path, c.stack)
+	c.timings = append(c.timings, &specTiming{
+		path:  append(path, s.Name),
+		stats: s.Stats,
+	})
 }
 
-func (t *testsuite) Add(c *testcase) {
-	t.Cases = append(t.Cases, c)
+// List the slowest specs, and how much of the whole run each took, followed by
+// the suites which spent the longest running their bodies again ahead of each
+// child.
+func (c *ConsoleReporter) printSlowest(total time.Duration) {
+	if c.slowest <= 0 || len(c.timings) == 0 {
+		return
+	}
+
+	slowest := c.topTimings(func(s *stats) time.Duration { return s.Duration })
+	var sum time.Duration
+	for _, t := range slowest {
+		sum += t.stats.Duration
+	}
+	fmt.Fprintf(c.w, "\nSlowest %d specs (%s, %.1f%% of %s)\n",
+		len(slowest), sum, share(sum, total), total)
+
+	for _, t := range slowest {
+		dur := fmt.Sprintf("%10s", t.stats.Duration)
+		if c.isSlow(t.stats.Duration) {
+			dur = c.colorize(dur, "yellow+b")
+		}
+		fmt.Fprintf(c.w, "  %s %5.1f%%  %s\n",
+			dur, share(t.stats.Duration, total), strings.Join(t.path, " > "))
+	}
+
+	replayed := c.topTimings(func(s *stats) time.Duration { return s.Replay })
+	if len(replayed) > 0 {
+		fmt.Fprintf(c.w, "\nMost setup replayed\n")
+		fmt.Fprintf(c.w, "  %10s %10s %10s\n", "replay", "self", "total")
+		for _, t := range replayed {
+			fmt.Fprintf(c.w, "  %10s %10s %10s  %s\n",
+				t.stats.Replay, t.stats.Duration, t.stats.Total,
+				strings.Join(t.path, " > "))
+		}
+	}
+	fmt.Fprintln(c.w)
 }
 
-type skipped struct {
-	Message string `xml:"message,attr"`
+// The timings with the largest non-zero values of the given measure, largest
+// first.
+func (c *ConsoleReporter) topTimings(measure func(*stats) time.Duration) []*specTiming {
+	timings := make([]*specTiming, 0, len(c.timings))
+	for _, t := range c.timings {
+		if measure(t.stats) > 0 {
+			timings = append(timings, t)
+		}
+	}
+	sort.SliceStable(timings, func(i, j int) bool {
+		return measure(timings[i].stats) > measure(timings[j].stats)
+	})
+	if len(timings) > c.slowest {
+		timings = timings[:c.slowest]
+	}
+	return timings
 }
 
-type testcase struct {
-	Classname string   `xml:"classname,attr"`
-	Name      string   `xml:"name,attr"`
-	Failures  []string `xml:"failure"`
-	Skipped   *skipped `xml:"skipped,omitempty"`
+// Failed suites which failed in the same way, listed together by the console.
+type failureGroup struct {
+	title    string
+	failures []*SuiteFailure
 }
 
-func (t *testcase) Fail(msg string) {
-	t.Failures = append(t.Failures, msg)
+// The kinds of failure in the order they're listed, worst first. Anything
+// without a kind is listed with the plain failures.
+var failureKinds = []struct {
+	kind  ErrorKind
+	title string
+}{
+	{KindPanic, "Panics"},
+	{KindTimeout, "Timeouts"},
+	{KindHook, "Hook failures"},
+	{KindAssertion, "Failed assertions"},
+	{KindFail, "Failures"},
 }
 
-func JUnit(w io.Writer) *JunitReporter {
-	enc := xml.NewEncoder(w)
-	enc.Indent("", "  ")
+// Group failed suites by the worst kind of error each failed with.
+func groupFailures(errs []*SuiteFailure) []*failureGroup {
+	groups := make([]*failureGroup, len(failureKinds))
+	for i, k := range failureKinds {
+		groups[i] = &failureGroup{title: k.title}
+	}
 
-	suite := &testsuite{
-		Cases: make([]*testcase, 0),
+	for _, err := range errs {
+		worst := len(failureKinds) - 1
+		for _, e := range err.errors {
+			for i, k := range failureKinds {
+				if e.Kind == k.kind && i < worst {
+					worst = i
+				}
+			}
+		}
+		groups[worst].failures = append(groups[worst].failures, err)
 	}
 
-	return &JunitReporter{
-		w:            w,
-		stack:        make([]string, 0),
-		enc:          enc,
-		invalidClass: regexp.MustCompile(`[^A-Za-z0-9]`),
-		invalidName:  regexp.MustCompile(`[^A-Za-z0-9 ]`),
-		suite:        suite,
+	found := make([]*failureGroup, 0)
+	for _, group := range groups {
+		if len(group.failures) > 0 {
+			found = append(found, group)
+		}
 	}
+	return found
 }
 
-func (j *JunitReporter) Start(s *suite) {
-	j.suite.Tests++
-	stack := append([]string{"test"}, j.stack...)
-	j.current = &testcase{
-		Classname: strings.Join(stack, "."),
-		Name:      j.name(s.Name),
-		Failures:  make([]string, 0),
+// The percentage of the total a duration is.
+func share(d, total time.Duration) float64 {
+	if total <= 0 {
+		return 0
 	}
+	return 100 * float64(d) / float64(total)
 }
 
-func (j *JunitReporter) Pass(*suite) {
-	j.suite.Add(j.current)
-	j.current = nil
-}
+func (c *ConsoleReporter) printSuiteFailure(err *SuiteFailure) {
+	fmt.Fprintln(c.w)
+	fmt.Fprintf(c.w, "  FAILURE in '%s'\n", err.suite.Name)
 
-func (j *JunitReporter) Fail(s *suite, errs []*TestError) {
-	for _, err := range errs {
-		j.current.Fail(err.Error())
+	for _, err := range err.errors {
+		fmt.Fprintf(c.w, "  %s %s:%d\n", c.colorize(fail, "red+b"), err.File, err.Line)
+		lines, e := err.Context(c.context, c.context)
+		if c.context > 0 && len(lines) > 0 && e == nil {
+			c.printContext(lines)
+		} else if src, e := err.Source(); e == nil {
+			fmt.Fprintf(c.w, "    %s\n", c.colorize(src, "white+b"))
+		}
+
+		for _, msg := range strings.Split(err.Error(), "\n") {
+			fmt.Fprintf(c.w, "      %s\n", msg)
+		}
+		if len(err.Differences) > 0 {
+			c.printDifferences(err.Differences)
+		}
+		if c.verbose && len(err.Stack) > 0 {
+			fmt.Fprintln(c.w)
+			for _, line := range strings.Split(formatStack(err.Stack), "\n") {
+				fmt.Fprintf(c.w, "      %s\n", c.colorize(line, "+h"))
+			}
+		}
+		fmt.Fprintln(c.w)
 	}
-	j.suite.Add(j.current)
-	j.current = nil
-}
+	if logs := err.suite.Logs; len(logs) > 0 {
+		fmt.Fprintf(c.w, "  %s\n", c.colorize("Log:", "yellow"))
+		for _, entry := range logs {
+			fmt.Fprintf(c.w, "    %s\n", entry)
+		}
+		fmt.Fprintln(c.w)
+	}
+	c.printOutput("stdout", err.suite.Stdout)
+	c.printOutput("stderr", err.suite.Stderr)
+}
+
+// Show each part of an expected value which differs from the actual one, the
+// expected parts in red and the actual in green.
+func (c *ConsoleReporter) printDifferences(diffs []*Difference) {
+	fmt.Fprintf(c.w, "\n      Differences (%s != %s):\n",
+		c.colorize("expected", "red"), c.colorize("actual", "green"))
+	for _, diff := range diffs {
+		path := diff.Path
+		if path == "" {
+			path = "value"
+		}
+		if diff.Lines == nil {
+			fmt.Fprintf(c.w, "        %s: %s != %s\n", path,
+				c.colorize(diff.Expected, "red"), c.colorize(diff.Actual, "green"))
+			continue
+		}
 
-func (j *JunitReporter) Skip(s *suite, e *TestError) {
-	j.current.Skipped = &skipped{e.Error()}
-	j.suite.Add(j.current)
-	j.current = nil
+		fmt.Fprintf(c.w, "        %s:\n", path)
+		for _, line := range diff.Lines {
+			switch line[0] {
+			case diffRemoved:
+				line = c.colorize(line, "red")
+			case diffAdded:
+				line = c.colorize(line, "green")
+			}
+			fmt.Fprintf(c.w, "          %s\n", line)
+		}
+	}
 }
 
-func (j *JunitReporter) Descend(s *suite) {
-	j.stack = append(j.stack, j.className(s.Name))
-}
+// Show the source around a failure, numbered, highlighted and with the failing
+// line marked. The lines are moved left as far as they can all go.
+func (c *ConsoleReporter) printContext(lines []*SourceLine) {
+	width := len(strconv.Itoa(lines[len(lines)-1].Number))
+	indent := -1
+	for _, line := range lines {
+		if strings.TrimSpace(line.Text) == "" {
+			continue
+		}
+		n := len(line.Text) - len(strings.TrimLeft(line.Text, " \t"))
+		if indent < 0 || n < indent {
+			indent = n
+		}
+	}
 
-func (j *JunitReporter) Ascend(*suite) {
-	j.stack = j.stack[0 : len(j.stack)-1]
+	for _, line := range lines {
+		text := ""
+		if len(line.Text) > indent && indent >= 0 {
+			text = line.Text[indent:]
+		}
+		marker := " "
+		if line.Failing {
+			marker = c.colorize(">", "red+b")
+		}
+		number := c.colorize(fmt.Sprintf("%*d", width, line.Number), "+h")
+		if c.color {
+			text = highlightGo(text, c.colorize)
+		}
+		fmt.Fprintf(c.w, "   %s %s | %s\n", marker, number, text)
+	}
 }
 
-func (j *JunitReporter) Begin() {
+// Show what a failing spec wrote while its output was captured.
+func (c *ConsoleReporter) printOutput(name, output string) {
+	output = strings.TrimRight(output, "\n")
+	if output == "" {
+		return
+	}
+	fmt.Fprintf(c.w, "  %s\n", c.colorize("Captured "+name+":", "yellow"))
+	for _, line := range strings.Split(output, "\n") {
+		fmt.Fprintf(c.w, "    %s\n", line)
+	}
+	fmt.Fprintln(c.w)
 }
 
-func (j *JunitReporter) Finish([]*SuiteFailure) {
-	fmt.Fprint(j.w, xml.Header)
-	j.enc.Encode(j.suite)
+func (c *ConsoleReporter) pad() int {
+	pad := "  "
+	for i := 0; i < c.depth; i++ {
+		fmt.Fprint(c.w, pad)
+	}
+	return c.depth * len(pad)
 }
 
-func (j *JunitReporter) className(s string) string {
-	s = strings.Title(s)
-	return j.invalidClass.ReplaceAllString(s, "")
+func (c *ConsoleReporter) colorize(s, style string) string {
+	if !c.color || s == "" {
+		return s
+	}
+	return ansi.Color(s, style)
 }
 
-func (j *JunitReporter) name(s string) string {
-	s = strings.Title(s)
-	return j.invalidName.ReplaceAllString(s, "")
+// Only files attached to a character device, such as a terminal, can have
+// their lines rewritten.
+func isTerminal(w io.Writer) bool {
+	f, ok := w.(*os.File)
+	if !ok {
+		return false
+	}
+	info, err := f.Stat()
+	if err != nil {
+		return false
+	}
+	return info.Mode()&os.ModeCharDevice != 0
 }
@@ -1,5 +1,13 @@
 package spec
 
+import (
+	"bytes"
+	"github.com/markchadwick/assert"
+	"strings"
+	"testing"
+	"time"
+)
+
 var nilReporter = &testReporter{}
 
 type testReporter struct {
@@ -34,3 +42,107 @@ func (t *testReporter) Begin() {
 
 func (t *testReporter) Finish(errs []*SuiteFailure) {
 }
+
+// Run the suite reporters are tested against, which has a spec with each
+// outcome. Its one failure is nested a level down.
+func runFixture(reporters ...Reporter) *runner {
+	r := Runner(Suite("Fixture suite", func(c *C) {
+		c.It("passes", func(c *C) {})
+		c.It("skips", func(c *C) {
+			c.Skip("later")
+		})
+		c.It("is pending", nil)
+		c.It("nests", func(c *C) {
+			c.It("fails", func(c *C) {
+				c.Failf("nope")
+			})
+		})
+	}))
+	r.Run(reporters...)
+	return r
+}
+
+func consoleFixture(opts ConsoleOptions) string {
+	buf := new(bytes.Buffer)
+	runFixture(ConsoleTo(buf, opts))
+	return buf.String()
+}
+
+func TestConsoleNotATerminal(t *testing.T) {
+	out := consoleFixture(ConsoleOptions{Color: "auto"})
+
+	assert.That(t, strings.Contains(out, "\r")).IsFalse()
+	assert.That(t, strings.Contains(out, "\x1b")).IsFalse()
+	assert.That(t, strings.Contains(out, "\n  \xE2\x9c\x93 passes ")).IsTrue()
+	assert.That(t, strings.Contains(out, "  FAILURE in 'fails'\n")).IsTrue()
+	assert.That(t, strings.Contains(out, "FAIL (6 specs in ")).IsTrue()
+}
+
+func TestConsoleColor(t *testing.T) {
+	never := consoleFixture(ConsoleOptions{Color: "never"})
+	assert.That(t, strings.Contains(never, "\x1b")).IsFalse()
+
+	always := consoleFixture(ConsoleOptions{Color: "always"})
+	assert.That(t, strings.Contains(always, "\x1b")).IsTrue()
+	assert.That(t, strings.Contains(always, "\r")).IsFalse()
+}
+
+func TestConsoleDots(t *testing.T) {
+	out := consoleFixture(ConsoleOptions{Color: "never", Format: "dots"})
+	assert.That(t, strings.HasPrefix(out, "\n..**.F\n")).IsTrue()
+	assert.That(t, strings.Contains(out, "FAILURE in 'fails'")).IsTrue()
+}
+
+func TestConsoleDocs(t *testing.T) {
+	out := consoleFixture(ConsoleOptions{Color: "never", Format: "docs"})
+	assert.That(t, strings.HasPrefix(out,
+		"\nFixture suite\n  passes\n  skips (SKIPPED: later)\n"+
+			"  is pending (SKIPPED: pending)\n  nests\n    fails (FAILED)\n")).IsTrue()
+}
+
+func TestConsoleFailuresOnly(t *testing.T) {
+	out := consoleFixture(ConsoleOptions{Color: "never", Format: "failures"})
+	assert.That(t, strings.HasPrefix(out, "\n\n\n-----")).IsTrue()
+	assert.That(t, strings.Contains(out, "passes")).IsFalse()
+	assert.That(t, strings.Contains(out, "FAILURE in 'fails'")).IsTrue()
+}
+
+func TestConsoleUnknownFormat(t *testing.T) {
+	out := consoleFixture(ConsoleOptions{Color: "never", Format: "doc"})
+	assert.That(t, strings.HasPrefix(out,
+		"spec: unknown console format \"doc\", using tree\n")).IsTrue()
+	assert.That(t, strings.Contains(out, "\n  \xE2\x9c\x93 passes ")).IsTrue()
+}
+
+func TestConsoleUnknownColor(t *testing.T) {
+	out := consoleFixture(ConsoleOptions{Color: "yes"})
+	assert.That(t, strings.HasPrefix(out,
+		"spec: unknown console color \"yes\", using auto\n")).IsTrue()
+
+	// Auto never colors output which isn't going to a terminal
+	assert.That(t, strings.Contains(out, "\x1b[")).IsFalse()
+}
+
+func TestConsoleSlowest(t *testing.T) {
+	buf := new(bytes.Buffer)
+	console := ConsoleTo(buf, ConsoleOptions{
+		Color:   "never",
+		Slow:    50 * time.Millisecond,
+		Slowest: 1,
+	})
+	Runner(Suite("Slow suite", func(c *C) {
+		c.It("is quick", func(c *C) {})
+		c.It("is slow", func(c *C) {
+			time.Sleep(100 * time.Millisecond)
+		})
+	})).Run(console)
+
+	out := buf.String()
+	assert.That(t, strings.Contains(out, "Slowest 1 specs (")).IsTrue()
+	assert.That(t, strings.Contains(out, "%  Slow suite > is slow\n")).IsTrue()
+	assert.That(t, strings.Contains(out, "is quick")).IsTrue()
+
+	lines := strings.Split(out, "\n")
+	assert.That(t, strings.HasSuffix(lines[3], " SLOW")).IsTrue()
+	assert.That(t, strings.HasSuffix(lines[2], " SLOW")).IsFalse()
+}
@@ -0,0 +1,234 @@
+package spec
+
+import (
+	"encoding/json"
+	"io"
+	"reflect"
+	"time"
+)
+
+// The outcome of a single spec.
+type Status string
+
+const (
+	Passed  Status = "pass"
+	Failed  Status = "fail"
+	Skipped Status = "skip"
+	Pending Status = "pending"
+)
+
+// A Result is the outcome of a single spec along with the outcomes of all of
+// its children. The root of a run has no name or status, and holds each of the
+// top-level suites as its children.
+//
+// Duration is the time spent in the spec's own body, Total includes all of its
+// children, and Replay is the time spent running its body again ahead of each
+// child. All are in nanoseconds when written as JSON.
+type Result struct {
+	Name     string         `json:"name,omitempty"`
+	Status   Status         `json:"status,omitempty"`
+	Duration time.Duration  `json:"duration"`
+	Total    time.Duration  `json:"total"`
+	Replay   time.Duration  `json:"replay"`
+	Errors   []*ResultError `json:"errors,omitempty"`
+	Skip     string         `json:"skip,omitempty"`
+	Stdout   string         `json:"stdout,omitempty"`
+	Stderr   string         `json:"stderr,omitempty"`
+	Logs     []*LogEntry    `json:"logs,omitempty"`
+	Children []*Result      `json:"children,omitempty"`
+}
+
+// A failure (or skip) recorded against a spec, along with where it happened.
+// The error itself is kept, though not written as JSON, so errors.Is and
+// errors.As can look for a particular one.
+//
+// The values of a failed comparison are written out as Go-like source, as they
+// would be in a diff. A failed `c.Assert` has only its Actual value.
+type ResultError struct {
+	Message  string        `json:"message"`
+	Kind     ErrorKind     `json:"kind,omitempty"`
+	Err      error         `json:"-"`
+	File     string        `json:"file,omitempty"`
+	Line     int           `json:"line,omitempty"`
+	Source   string        `json:"source,omitempty"`
+	Expected string        `json:"expected,omitempty"`
+	Actual   string        `json:"actual,omitempty"`
+	Operator string        `json:"operator,omitempty"`
+	Diff     string        `json:"diff,omitempty"`
+	Stack    []*StackFrame `json:"stack,omitempty"`
+}
+
+func newResultError(err *TestError) *ResultError {
+	src, _ := err.Source()
+	result := &ResultError{
+		Message:  err.Error(),
+		Kind:     err.Kind,
+		Err:      err.Err,
+		File:     err.File,
+		Line:     err.Line,
+		Source:   src,
+		Operator: err.Operator,
+		Diff:     err.Diff,
+		Stack:    err.Stack,
+	}
+	if err.Operator != "" {
+		result.Expected = formatValue(reflect.ValueOf(err.Expected))
+	}
+	if err.Kind == KindAssertion {
+		result.Actual = formatValue(reflect.ValueOf(err.Actual))
+	}
+	return result
+}
+
+func (e *ResultError) Error() string {
+	return e.Message
+}
+
+func (e *ResultError) Unwrap() error {
+	return e.Err
+}
+
+// Tallies of the specs under a result.
+type Counts struct {
+	Specs   int `json:"specs"`
+	Passed  int `json:"passed"`
+	Failed  int `json:"failed"`
+	Skipped int `json:"skipped"`
+	Pending int `json:"pending"`
+}
+
+// Visit this result and each of its descendants depth-first, parents before
+// children. The path holds the names from the top-level suite down to, and
+// including, the visited result. Returning an error stops the walk, and that
+// error is returned.
+func (r *Result) Walk(fn func(path []string, r *Result) error) error {
+	return r.walk(nil, fn)
+}
+
+func (r *Result) walk(path []string, fn func([]string, *Result) error) error {
+	if r.Status != "" {
+		path = append(path[:len(path):len(path)], r.Name)
+		if err := fn(path, r); err != nil {
+			return err
+		}
+	}
+	for _, child := range r.Children {
+		if err := child.walk(path, fn); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// All results in this tree for which the given function is true, in the order
+// they ran.
+func (r *Result) Filter(fn func(*Result) bool) []*Result {
+	results := make([]*Result, 0)
+	r.Walk(func(_ []string, r *Result) error {
+		if fn(r) {
+			results = append(results, r)
+		}
+		return nil
+	})
+	return results
+}
+
+// Count the specs in this tree by their status.
+func (r *Result) Counts() Counts {
+	var counts Counts
+	r.Walk(func(_ []string, r *Result) error {
+		counts.Specs++
+		switch r.Status {
+		case Passed:
+			counts.Passed++
+		case Failed:
+			counts.Failed++
+		case Skipped:
+			counts.Skipped++
+		case Pending:
+			counts.Pending++
+		}
+		return nil
+	})
+	return counts
+}
+
+// Write this tree as indented JSON.
+func (r *Result) WriteJSON(w io.Writer) error {
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+	return enc.Encode(r)
+}
+
+// ----------------------------------------------------------------------------
+// Result builder
+// ----------------------------------------------------------------------------
+
+// A reporter which assembles a Result tree as suites run.
+type resultBuilder struct {
+	root    *Result
+	stack   []*Result
+	current *Result
+	start   time.Time
+}
+
+func newResultBuilder() *resultBuilder {
+	return &resultBuilder{}
+}
+
+func (b *resultBuilder) Start(s *suite) {
+	b.current = &Result{Name: s.Name}
+	parent := b.stack[len(b.stack)-1]
+	parent.Children = append(parent.Children, b.current)
+}
+
+func (b *resultBuilder) Pass(s *suite) {
+	b.current.Status = Passed
+	b.current.Stdout, b.current.Stderr = s.Stdout, s.Stderr
+	b.current.Logs = s.Logs
+	b.current.Duration = s.Stats.Duration
+}
+
+func (b *resultBuilder) Fail(s *suite, errs []*TestError) {
+	b.current.Status = Failed
+	b.current.Stdout, b.current.Stderr = s.Stdout, s.Stderr
+	b.current.Logs = s.Logs
+	b.current.Duration = s.Stats.Duration
+	b.current.Total = s.Stats.Total
+	for _, err := range errs {
+		b.current.Errors = append(b.current.Errors, newResultError(err))
+	}
+}
+
+func (b *resultBuilder) Skip(s *suite, skip *TestError) {
+	b.current.Status = Skipped
+	if skip.pending {
+		b.current.Status = Pending
+	}
+	b.current.Duration = s.Stats.Duration
+	b.current.Total = s.Stats.Total
+	b.current.Skip = skip.Error()
+	b.current.Stdout, b.current.Stderr = s.Stdout, s.Stderr
+	b.current.Logs = s.Logs
+}
+
+func (b *resultBuilder) Descend(*suite) {
+	b.stack = append(b.stack, b.current)
+}
+
+func (b *resultBuilder) Ascend(s *suite) {
+	b.current = b.stack[len(b.stack)-1]
+	b.current.Total = s.Stats.Total
+	b.current.Replay = s.Stats.Replay
+	b.stack = b.stack[0 : len(b.stack)-1]
+}
+
+func (b *resultBuilder) Begin() {
+	b.root = &Result{}
+	b.stack = []*Result{b.root}
+	b.start = time.Now()
+}
+
+func (b *resultBuilder) Finish([]*SuiteFailure) {
+	b.root.Duration = time.Now().Sub(b.start)
+}
@@ -0,0 +1,70 @@
+package spec
+
+import (
+	"bytes"
+	"encoding/json"
+	"github.com/markchadwick/assert"
+	"testing"
+)
+
+func TestResultTree(t *testing.T) {
+	result := runFixture().Result()
+	assert.That(t, result.Children).HasLen(1)
+
+	top := result.Children[0]
+	assert.That(t, top.Name).Equals("Fixture suite")
+	assert.That(t, top.Status).Equals(Passed)
+	assert.That(t, top.Children).HasLen(4)
+
+	skipped := top.Children[1]
+	assert.That(t, skipped.Status).Equals(Skipped)
+	assert.That(t, skipped.Skip).Equals("later")
+	assert.That(t, top.Children[2].Status).Equals(Pending)
+
+	failed := top.Children[3].Children[0]
+	assert.That(t, failed.Status).Equals(Failed)
+	assert.That(t, failed.Errors).HasLen(1)
+	assert.That(t, failed.Errors[0].Message).Equals("nope")
+	assert.That(t, failed.Errors[0].Source).Equals(`c.Failf("nope")`)
+	assert.That(t, failed.Errors[0].Line > 0).IsTrue()
+}
+
+func TestResultCounts(t *testing.T) {
+	counts := runFixture().Result().Counts()
+	assert.That(t, counts).Equals(Counts{
+		Specs:   6,
+		Passed:  3,
+		Failed:  1,
+		Skipped: 1,
+		Pending: 1,
+	})
+}
+
+func TestResultWalkPaths(t *testing.T) {
+	paths := make([]string, 0)
+	runFixture().Result().Walk(func(path []string, r *Result) error {
+		if len(path) == 3 {
+			paths = append(paths, path[0]+"/"+path[1]+"/"+path[2])
+		}
+		return nil
+	})
+	assert.That(t, paths).Equals([]string{"Fixture suite/nests/fails"})
+}
+
+func TestResultFilter(t *testing.T) {
+	failed := runFixture().Result().Filter(func(r *Result) bool {
+		return r.Status == Failed
+	})
+	assert.That(t, failed).HasLen(1)
+	assert.That(t, failed[0].Name).Equals("fails")
+}
+
+func TestResultJSON(t *testing.T) {
+	buf := new(bytes.Buffer)
+	assert.That(t, runFixture().Result().WriteJSON(buf)).IsNil()
+
+	decoded := new(Result)
+	assert.That(t, json.Unmarshal(buf.Bytes(), decoded)).IsNil()
+	assert.That(t, decoded.Children[0].Children[3].Children[0].Errors[0].Message).
+		Equals("nope")
+}
@@ -1,12 +1,16 @@
 package spec
 
 import (
+	"flag"
 	"fmt"
 	"sync"
 )
 
 var DefaultRunner = Runner()
 
+var captureFlag = flag.Bool("spec.capture", false,
+	"Capture what each spec writes to stdout and stderr")
+
 // TODO:
 //  - Flags
 //    -spec.f filename
@@ -15,6 +19,11 @@ type runner struct {
 	reporters []Reporter
 	runLock   *sync.Mutex
 	errors    []*SuiteFailure
+	result    *resultBuilder
+
+	// Capture what each spec writes to os.Stdout and os.Stderr, rather than
+	// letting it through to the terminal. Also turned on by -spec.capture.
+	Capture bool
 }
 
 func Runner(suites ...*suite) *runner {
@@ -31,16 +40,19 @@ func (r *runner) Add(s *suite) {
 
 // Run this suite reporting test conditions to each of the given reporters.
 // Tests will only be run once, and their results broadcast to each reporter.
+// The full outcome of the run is available from `Result` once this returns.
 func (r *runner) Run(reporters ...Reporter) error {
 	r.runLock.Lock()
 	defer r.runLock.Unlock()
 
-	r.reporters = reporters
+	r.result = newResultBuilder()
+	r.reporters = append([]Reporter{r.result}, reporters...)
 	r.errors = make([]*SuiteFailure, 0)
 
 	r.Begin()
 
 	for _, suite := range r.suites {
+		suite.capture = r.Capture || *captureFlag
 		suite.Run(r)
 	}
 
@@ -52,6 +64,18 @@ func (r *runner) Run(reporters ...Reporter) error {
 	return fmt.Errorf("%d test failures", len(r.errors))
 }
 
+// The outcome of every spec from the most recent call to `Run`, or nil if this
+// runner has not been run.
+func (r *runner) Result() *Result {
+	r.runLock.Lock()
+	defer r.runLock.Unlock()
+
+	if r.result == nil {
+		return nil
+	}
+	return r.result.root
+}
+
 func (r *runner) Start(s *suite) {
 	for _, r := range r.reporters {
 		r.Start(s)
@@ -0,0 +1,129 @@
+package spec
+
+import (
+	"bufio"
+	"go/scanner"
+	"go/token"
+	"os"
+	"sync"
+)
+
+// A line of source code surrounding a failure.
+type SourceLine struct {
+	Number  int
+	Text    string
+	Failing bool
+}
+
+// Source files are read at most once, however many failures point into them,
+// and shared by every reporter.
+var sources = &sourceCache{files: make(map[string][]string)}
+
+type sourceCache struct {
+	lock  sync.Mutex
+	files map[string][]string
+}
+
+// Every line of a file, read from disk only the first time it's asked for.
+// Files which can't be read are tried again next time.
+func (c *sourceCache) lines(fname string) ([]string, error) {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+
+	if lines, ok := c.files[fname]; ok {
+		return lines, nil
+	}
+
+	file, err := os.Open(fname)
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+
+	lines := make([]string, 0)
+	scanner := bufio.NewScanner(file)
+	for scanner.Scan() {
+		lines = append(lines, scanner.Text())
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+	c.files[fname] = lines
+	return lines, nil
+}
+
+// The given line of a file, with up to `before` lines ahead of it and `after`
+// lines following it. Fewer lines are returned at either end of the file.
+func sourceContext(fname string, line, before, after int) ([]*SourceLine, error) {
+	text, err := sources.lines(fname)
+	if err != nil {
+		return nil, err
+	}
+
+	if before < 0 {
+		before = 0
+	}
+	if after < 0 {
+		after = 0
+	}
+	from, to := line-before, line+after
+	if from < 1 {
+		from = 1
+	}
+	if to > len(text) {
+		to = len(text)
+	}
+
+	lines := make([]*SourceLine, 0)
+	for i := from; i <= to; i++ {
+		lines = append(lines, &SourceLine{
+			Number:  i,
+			Text:    text[i-1],
+			Failing: i == line,
+		})
+	}
+	return lines, nil
+}
+
+// Color a line of Go by what each of its tokens is, using the given function
+// to apply each style. Lines are highlighted on their own, so the middle of a
+// multi-line string or comment may come out oddly.
+func highlightGo(line string, colorize func(s, style string) string) string {
+	src := []byte(line)
+	fset := token.NewFileSet()
+	file := fset.AddFile("", fset.Base(), len(src))
+
+	var s scanner.Scanner
+	s.Init(file, src, func(token.Position, string) {}, scanner.ScanComments)
+
+	out := ""
+	last := 0
+	for {
+		pos, tok, lit := s.Scan()
+		if tok == token.EOF {
+			break
+		}
+		style := ""
+		switch {
+		case tok.IsKeyword():
+			style = "blue+b"
+		case tok == token.STRING || tok == token.CHAR:
+			style = "green"
+		case tok == token.INT || tok == token.FLOAT || tok == token.IMAG:
+			style = "magenta"
+		case tok == token.COMMENT:
+			style = "black+h"
+		}
+		if style == "" || lit == "" {
+			continue
+		}
+
+		offset := file.Offset(pos)
+		if offset < last || offset+len(lit) > len(src) {
+			continue
+		}
+		out += line[last:offset] + colorize(lit, style)
+		last = offset + len(lit)
+	}
+	return out + line[last:]
+}
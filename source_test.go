@@ -0,0 +1,53 @@
+package spec
+
+import (
+	"bytes"
+	"fmt"
+	"github.com/markchadwick/assert"
+	"strings"
+	"testing"
+)
+
+func TestSourceContext(t *testing.T) {
+	lines, err := sourceContext("source_test.go", 2, 3, 1)
+	assert.That(t, err).IsNil()
+	assert.That(t, lines).HasLen(3)
+	assert.That(t, lines[0].Number).Equals(1)
+	assert.That(t, lines[0].Text).Equals("package spec")
+	assert.That(t, lines[1].Failing).IsTrue()
+	assert.That(t, lines[2].Failing).IsFalse()
+
+	_, err = sourceContext("no_such_file.go", 1, 1, 1)
+	assert.That(t, err).NotNil()
+}
+
+func TestSourceCache(t *testing.T) {
+	first, _ := sources.lines("source_test.go")
+	second, _ := sources.lines("source_test.go")
+	assert.That(t, &first[0] == &second[0]).IsTrue()
+}
+
+func TestHighlightGo(t *testing.T) {
+	brackets := func(s, style string) string {
+		return "[" + style + ":" + s + "]"
+	}
+	line := highlightGo(`	if n := 42; n > 0 { return "yes" } // done`, brackets)
+	assert.That(t, line).Equals(
+		`	[blue+b:if] n := [magenta:42]; n > [magenta:0] { ` +
+			`[blue+b:return] [green:"yes"] } [black+h:// done]`)
+}
+
+func TestConsoleContext(t *testing.T) {
+	var line int
+	buf := new(bytes.Buffer)
+	Runner(Suite("Context suite", func(c *C) {
+		line = nextLine()
+		c.Failf("nope")
+	})).Run(ConsoleTo(buf, ConsoleOptions{Color: "never", Context: 1}))
+
+	out := buf.String()
+	assert.That(t, strings.Contains(out, fmt.Sprintf(
+		"     %d | \tline = nextLine()\n"+
+			"   > %d | \tc.Failf(\"nope\")\n"+
+			"     %d | })).Run(", line-1, line, line+1))).IsTrue()
+}
@@ -80,6 +80,32 @@ func TestRunChild(t *testing.T) {
 	assert.That(t, child2Runs).Equals(1)
 }
 
+func TestRunChildReplayFailures(t *testing.T) {
+	fickleRuns, vanishingRuns := 0, 0
+	r := Runner(Suite("Fickle suite", func(c *C) {
+		fickleRuns++
+		if fickleRuns > 1 {
+			panic("changed my mind")
+		}
+		c.It("child", func(c *C) {})
+	}), Suite("Vanishing suite", func(c *C) {
+		vanishingRuns++
+		if vanishingRuns == 1 {
+			c.It("child", func(c *C) {})
+		}
+	}))
+	r.Run(nilReporter)
+
+	fickle := r.Result().Children[0].Children[0]
+	assert.That(t, fickle.Status).Equals(Failed)
+	assert.That(t, fickle.Errors[0].Kind).Equals(KindPanic)
+
+	vanished := r.Result().Children[1].Children[0]
+	assert.That(t, vanished.Name).Equals("child")
+	assert.That(t, vanished.Status).Equals(Failed)
+	assert.That(t, vanished.Errors[0].Message).Equals("Found no child named 'child'")
+}
+
 func TestEqualChildren(t *testing.T) {
 	suiteRuns := 0
 	child1Runs := 0
@@ -0,0 +1,103 @@
+package spec
+
+import (
+	"fmt"
+	"path/filepath"
+	"runtime"
+	"strings"
+)
+
+// A single call in the stack leading up to a failure.
+type StackFrame struct {
+	Function string `json:"function"`
+	File     string `json:"file"`
+	Line     int    `json:"line"`
+}
+
+// The directory holding this package's source, whose frames (besides those of
+// its tests) are left out of stacks as they're the same for every failure.
+var specDir = func() string {
+	_, file, _, _ := runtime.Caller(0)
+	return filepath.Dir(file)
+}()
+
+// Mark the calling function as a helper. Failures and logs from within it are
+// reported at the line which called it instead, as with testing.T's Helper.
+func (c *C) Helper() {
+	pc, _, _, ok := runtime.Caller(1)
+	if !ok {
+		return
+	}
+	name := runtime.FuncForPC(pc).Name()
+
+	c.lock.Lock()
+	defer c.lock.Unlock()
+	if c.helpers == nil {
+		c.helpers = make(map[string]bool)
+	}
+	c.helpers[name] = true
+}
+
+func (c *C) isHelper(function string) bool {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+	return c.helpers[function]
+}
+
+// The stack of the caller `skip` frames above this one, innermost first, without
+// the runtime's, the testing package's or this package's own frames.
+func stack(skip int) []*StackFrame {
+	// A full buffer may have cut the stack short, so keep growing it until
+	// there's room to spare
+	pcs := make([]uintptr, 64)
+	n := runtime.Callers(skip+2, pcs)
+	for n == len(pcs) {
+		pcs = make([]uintptr, 2*len(pcs))
+		n = runtime.Callers(skip+2, pcs)
+	}
+	frames := runtime.CallersFrames(pcs[:n])
+
+	stack := make([]*StackFrame, 0)
+	for {
+		frame, more := frames.Next()
+		if !internalFrame(frame) {
+			stack = append(stack, &StackFrame{
+				Function: frame.Function,
+				File:     frame.File,
+				Line:     frame.Line,
+			})
+		}
+		if !more {
+			return stack
+		}
+	}
+}
+
+func internalFrame(frame runtime.Frame) bool {
+	if strings.HasPrefix(frame.Function, "runtime.") ||
+		strings.HasPrefix(frame.Function, "testing.") {
+		return true
+	}
+	return filepath.Dir(frame.File) == specDir &&
+		!strings.HasSuffix(frame.File, "_test.go")
+}
+
+// The first frame of a stack outside of any helper, or nil if there is none.
+func (c *C) caller(stack []*StackFrame) *StackFrame {
+	for _, frame := range stack {
+		if !c.isHelper(frame.Function) {
+			return frame
+		}
+	}
+	return nil
+}
+
+// A stack as Go prints one: each function followed by its indented location.
+func formatStack(stack []*StackFrame) string {
+	lines := make([]string, 0, 2*len(stack))
+	for _, frame := range stack {
+		lines = append(lines, frame.Function)
+		lines = append(lines, fmt.Sprintf("\t%s:%d", frame.File, frame.Line))
+	}
+	return strings.Join(lines, "\n")
+}
@@ -0,0 +1,63 @@
+package spec
+
+import (
+	"fmt"
+	"github.com/markchadwick/assert"
+	"strings"
+	"testing"
+)
+
+func TestHelper(t *testing.T) {
+	var failLine, callLine int
+	checkPositive := func(c *C, n int) {
+		c.Helper()
+		c.Logf("checking %d", n)
+		if n <= 0 {
+			failLine = nextLine()
+			c.Failf("%d is not positive", n)
+		}
+	}
+
+	r := Runner(Suite("Helper suite", func(c *C) {
+		callLine = nextLine()
+		checkPositive(c, -1)
+	}))
+	r.Run(nilReporter)
+
+	failure := r.Result().Children[0]
+	assert.That(t, failure.Errors).HasLen(1)
+	assert.That(t, failure.Errors[0].Source).Equals("checkPositive(c, -1)")
+	assert.That(t, failure.Logs[0].Line).Equals(callLine)
+
+	// The helper is still in the stack, beneath the spec which called it
+	stack := failure.Errors[0].Stack
+	assert.That(t, stack[0].Line).Equals(failLine)
+	assert.That(t, stack[1].Line).Equals(callLine)
+}
+
+func TestStackFiltering(t *testing.T) {
+	c := &C{}
+	line := nextLine()
+	c.Failf("nope")
+	stack := c.errors[0].Stack
+
+	assert.That(t, stack).HasLen(1)
+	assert.That(t, stack[0].Function).Equals("github.com/markchadwick/spec.TestStackFiltering")
+	assert.That(t, stack[0].Line).Equals(line)
+
+	assert.That(t, strings.HasPrefix(formatStack(stack),
+		"github.com/markchadwick/spec.TestStackFiltering\n\t/")).IsTrue()
+	assert.That(t, strings.HasSuffix(formatStack(stack), fmt.Sprintf("stack_test.go:%d", line))).
+		IsTrue()
+}
+
+func TestDeepStack(t *testing.T) {
+	var recurse func(depth int) []*StackFrame
+	recurse = func(depth int) []*StackFrame {
+		if depth == 0 {
+			return stack(0)
+		}
+		return recurse(depth - 1)
+	}
+	assert.That(t, len(recurse(200)) > 200).IsTrue()
+}
@@ -0,0 +1,198 @@
+package spec
+
+import (
+	"bytes"
+	"encoding/binary"
+	"fmt"
+	"hash/crc32"
+	"io"
+	"strings"
+	"time"
+)
+
+// Flags of a subunit v2 packet. The lowest three bits hold the test status.
+const (
+	subunitSignature = 0xb3
+	subunitVersion   = 0x2000
+
+	subunitTestID      = 0x0800
+	subunitRouteCode   = 0x0400
+	subunitTimestamp   = 0x0200
+	subunitRunnable    = 0x0100
+	subunitTags        = 0x0080
+	subunitFileContent = 0x0040
+	subunitMimeType    = 0x0020
+	subunitEOF         = 0x0010
+)
+
+// Test statuses of a subunit v2 packet.
+const (
+	subunitUndefined = iota
+	subunitExists
+	subunitInProgress
+	subunitSuccess
+	subunitUnexpectedSuccess
+	subunitSkip
+	subunitFail
+	subunitExpectedFail
+)
+
+// Packets can be no longer than 4MiB, so attachments are cut well short of it.
+const subunitMaxFile = 1 << 20
+
+// A reporter which writes the subunit v2 binary protocol, as read by stestr,
+// subunit2html and subunit-trace. Specs are identified by the names of the
+// suites above them and their own, joined by slashes.
+type SubunitReporter struct {
+	w     io.Writer
+	stack []string
+	id    string
+}
+
+type subunitPacket struct {
+	status   int
+	testID   string
+	time     time.Time
+	runnable bool
+	mime     string
+	fileName string
+	file     []byte
+	eof      bool
+}
+
+func Subunit(w io.Writer) *SubunitReporter {
+	return &SubunitReporter{
+		w:     w,
+		stack: make([]string, 0),
+	}
+}
+
+func (r *SubunitReporter) Start(s *suite) {
+	r.id = strings.Join(append(r.stack, s.Name), "/")
+	r.write(&subunitPacket{status: subunitInProgress, runnable: true})
+}
+
+func (r *SubunitReporter) Pass(s *suite) {
+	r.write(&subunitPacket{status: subunitSuccess, runnable: true})
+}
+
+func (r *SubunitReporter) Fail(s *suite, errs []*TestError) {
+	details := make([]string, len(errs))
+	for i, err := range errs {
+		details[i] = fmt.Sprintf("%s:%d\n", err.File, err.Line)
+		if src, e := err.Source(); e == nil {
+			details[i] += fmt.Sprintf("    %s\n", src)
+		}
+		details[i] += err.Error()
+	}
+	r.attach("traceback", strings.Join(details, "\n\n"))
+	r.write(&subunitPacket{status: subunitFail, runnable: true})
+}
+
+func (r *SubunitReporter) Skip(s *suite, skip *TestError) {
+	r.attach("reason", skip.Error())
+	r.write(&subunitPacket{status: subunitSkip, runnable: true})
+}
+
+func (r *SubunitReporter) Descend(s *suite) {
+	r.stack = append(r.stack, s.Name)
+}
+
+func (r *SubunitReporter) Ascend(*suite) {
+	r.stack = r.stack[0 : len(r.stack)-1]
+}
+
+func (r *SubunitReporter) Begin() {
+}
+
+func (r *SubunitReporter) Finish([]*SuiteFailure) {
+}
+
+// Attach a text file to the current spec.
+func (r *SubunitReporter) attach(name, content string) {
+	file := []byte(content)
+	if len(file) > subunitMaxFile {
+		file = file[:subunitMaxFile]
+	}
+	r.write(&subunitPacket{
+		mime:     "text/plain;charset=utf8",
+		fileName: name,
+		file:     file,
+		eof:      true,
+	})
+}
+
+func (r *SubunitReporter) write(p *subunitPacket) {
+	p.testID = r.id
+	p.time = time.Now()
+	r.w.Write(p.encode())
+}
+
+// The bytes of this packet on the wire.
+func (p *subunitPacket) encode() []byte {
+	flags := uint16(subunitVersion | p.status)
+	body := new(bytes.Buffer)
+
+	if !p.time.IsZero() {
+		flags |= subunitTimestamp
+		binary.Write(body, binary.BigEndian, uint32(p.time.Unix()))
+		body.Write(subunitVarint(uint32(p.time.Nanosecond())))
+	}
+	if p.testID != "" {
+		flags |= subunitTestID
+		subunitString(body, p.testID)
+	}
+	if p.runnable {
+		flags |= subunitRunnable
+	}
+	if p.mime != "" {
+		flags |= subunitMimeType
+		subunitString(body, p.mime)
+	}
+	if p.fileName != "" {
+		flags |= subunitFileContent
+		subunitString(body, p.fileName)
+		body.Write(subunitVarint(uint32(len(p.file))))
+		body.Write(p.file)
+	}
+	if p.eof {
+		flags |= subunitEOF
+	}
+
+	// The length counts the whole packet, including itself, so its own size
+	// must be settled first
+	length := 1 + 2 + body.Len() + 4
+	for _, size := range []int{1, 2, 3} {
+		if length+size < 1<<uint(8*size-2) {
+			length += size
+			break
+		}
+	}
+
+	packet := new(bytes.Buffer)
+	packet.WriteByte(subunitSignature)
+	binary.Write(packet, binary.BigEndian, flags)
+	packet.Write(subunitVarint(uint32(length)))
+	packet.Write(body.Bytes())
+	binary.Write(packet, binary.BigEndian, crc32.ChecksumIEEE(packet.Bytes()))
+	return packet.Bytes()
+}
+
+// Subunit's variable length integers use the top two bits of the first byte
+// to say how many more bytes follow.
+func subunitVarint(n uint32) []byte {
+	switch {
+	case n < 1<<6:
+		return []byte{byte(n)}
+	case n < 1<<14:
+		return []byte{byte(n>>8) | 0x40, byte(n)}
+	case n < 1<<22:
+		return []byte{byte(n>>16) | 0x80, byte(n >> 8), byte(n)}
+	}
+	return []byte{byte(n>>24) | 0xc0, byte(n >> 16), byte(n >> 8), byte(n)}
+}
+
+func subunitString(w *bytes.Buffer, s string) {
+	w.Write(subunitVarint(uint32(len(s))))
+	w.WriteString(s)
+}
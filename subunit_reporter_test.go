@@ -0,0 +1,77 @@
+package spec
+
+import (
+	"bytes"
+	"encoding/binary"
+	"encoding/hex"
+	"github.com/markchadwick/assert"
+	"hash/crc32"
+	"testing"
+)
+
+func TestSubunitPacket(t *testing.T) {
+	// The example from subunit's own documentation
+	packet := (&subunitPacket{
+		status:   subunitExists,
+		testID:   "foo",
+		runnable: true,
+	}).encode()
+	assert.That(t, hex.EncodeToString(packet)).Equals("b329010c03666f6f08555f1b")
+}
+
+func TestSubunitVarint(t *testing.T) {
+	assert.That(t, subunitVarint(63)).Equals([]byte{0x3f})
+	assert.That(t, subunitVarint(64)).Equals([]byte{0x40, 0x40})
+	assert.That(t, subunitVarint(16384)).Equals([]byte{0x80, 0x40, 0x00})
+	assert.That(t, subunitVarint(1<<22)).Equals([]byte{0xc0, 0x40, 0x00, 0x00})
+}
+
+func TestSubunitLongPacket(t *testing.T) {
+	packet := (&subunitPacket{
+		fileName: "big",
+		file:     make([]byte, 20000),
+	}).encode()
+
+	// Three bytes of length, whose value is the length of the whole packet
+	assert.That(t, packet[3]&0xc0).Equals(byte(0x80))
+	length := int(packet[3]&0x3f)<<16 | int(packet[4])<<8 | int(packet[5])
+	assert.That(t, length).Equals(len(packet))
+}
+
+func TestSubunitStream(t *testing.T) {
+	buf := new(bytes.Buffer)
+	runFixture(Subunit(buf))
+
+	statuses := make([]int, 0)
+	files := make([]int, 0)
+	data := buf.Bytes()
+	for len(data) > 0 {
+		assert.That(t, data[0]).Equals(byte(subunitSignature))
+		flags := binary.BigEndian.Uint16(data[1:3])
+		length := int(data[3])
+		if length&0xc0 == 0x40 {
+			length = int(binary.BigEndian.Uint16(data[3:5]) & 0x3fff)
+		}
+
+		packet := data[:length]
+		crc := binary.BigEndian.Uint32(packet[length-4:])
+		assert.That(t, crc).Equals(crc32.ChecksumIEEE(packet[:length-4]))
+
+		statuses = append(statuses, int(flags&0x7))
+		files = append(files, int(flags&subunitFileContent))
+		data = data[length:]
+	}
+
+	assert.That(t, statuses).Equals([]int{
+		subunitInProgress, subunitSuccess,
+		subunitInProgress, subunitSuccess,
+		subunitInProgress, subunitUndefined, subunitSkip,
+		subunitInProgress, subunitUndefined, subunitSkip,
+		subunitInProgress, subunitSuccess,
+		subunitInProgress, subunitUndefined, subunitFail,
+	})
+	assert.That(t, files[5]).Equals(subunitFileContent)
+	assert.That(t, files[13]).Equals(subunitFileContent)
+	assert.That(t, bytes.Contains(buf.Bytes(), []byte("Fixture suite/nests/fails"))).
+		IsTrue()
+}
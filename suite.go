@@ -1,6 +1,7 @@
 package spec
 
 import (
+	"errors"
 	"fmt"
 	"testing"
 	"time"
@@ -13,7 +14,15 @@ func Run(t *testing.T) {
 }
 
 type stats struct {
+	// Time spent running the suite's own body, not counting its children
 	Duration time.Duration
+
+	// Time spent running the suite and everything beneath it, including each
+	// time its body was run again ahead of a child
+	Total time.Duration
+
+	// Time spent running the suite's body again ahead of each of its children
+	Replay time.Duration
 }
 
 type suite struct {
@@ -22,6 +31,16 @@ type suite struct {
 	Stats    *stats
 	children []*suite
 	ctx      *C
+
+	// What the suite's body wrote to os.Stdout and os.Stderr, when output is
+	// being captured
+	Stdout string
+	Stderr string
+
+	// What the suite's body logged with `Log` and `Logf`
+	Logs []*LogEntry
+
+	capture bool
 }
 
 type Test func(c *C)
@@ -48,6 +67,8 @@ func (s *suite) Run(reporter Reporter) {
 	start := time.Now()
 	errs, skip := s.run(reporter)
 	s.Stats.Duration = time.Now().Sub(start)
+	s.Stats.Total = s.Stats.Duration
+	s.Stats.Replay = 0
 
 	if skip != nil {
 		reporter.Skip(s, skip)
@@ -59,6 +80,7 @@ func (s *suite) Run(reporter Reporter) {
 		for _, child := range s.children {
 			s.runChild(child, reporter)
 		}
+		s.Stats.Total = time.Now().Sub(start)
 		reporter.Ascend(s)
 	}
 }
@@ -70,17 +92,20 @@ func (s *suite) Run(reporter Reporter) {
 func (s *suite) run(reporter Reporter) (errs []*TestError, skip *TestError) {
 	skip = &TestError{}
 
-	// Capture Skip tests, which panic to halt execution of the test. TODO: Also
-	// fatal tests.
-	defer func() {
-		if r := recover(); r != nil {
-			if s, ok := r.(*TestError); ok && s.skip {
-				skip = s
-				return
-			}
-			panic(r)
+	// A suite with no body is pending, and is reported as a skip
+	if s.Test == nil {
+		return nil, &TestError{
+			Err:     errors.New("pending"),
+			Kind:    KindSkip,
+			skip:    true,
+			pending: true,
 		}
-	}()
+	}
+
+	if s.capture {
+		out := startCapture()
+		defer func() { s.Stdout, s.Stderr = out.stop() }()
+	}
 
 	if s.children == nil {
 		s.children = make([]*suite, 0)
@@ -93,31 +118,96 @@ func (s *suite) run(reporter Reporter) (errs []*TestError, skip *TestError) {
 	s.ctx.errors = make([]*TestError, 0)
 	defer func() { s.ctx.errors = nil }()
 
+	s.ctx.logs = make([]*LogEntry, 0)
+	defer func() {
+		s.Logs = s.ctx.logs
+		s.ctx.logs = nil
+	}()
+
+	running.push(s.ctx)
+	defer running.pop()
+
+	// Capture Skip tests, which panic to halt execution of the test. Any other
+	// panic fails the test, along with whatever else it had failed with.
+	defer func() {
+		if r := recover(); r != nil {
+			if e, ok := r.(*TestError); ok && e.skip {
+				skip = e
+				return
+			}
+			errs, skip = append(s.ctx.errors, s.panicked(r)), nil
+		}
+	}()
+
 	s.Test(s.ctx)
 	return s.ctx.errors, nil
 }
 
+// The failure for a panic recovered while running this suite, pointing at the
+// line which panicked.
+func (s *suite) panicked(r interface{}) *TestError {
+	// Only a value which was an error is wrapped, for errors.Is and errors.As
+	// to find
+	err := fmt.Errorf("panic: %v", r)
+	if e, ok := r.(error); ok {
+		err = fmt.Errorf("panic: %w", e)
+	}
+	testError := &TestError{
+		Err:   err,
+		Kind:  KindPanic,
+		Stack: stack(1),
+	}
+	if frame := s.ctx.caller(testError.Stack); frame != nil {
+		testError.File, testError.Line = frame.File, frame.Line
+	}
+	return testError
+}
+
 // Run this suite only descending into the given child. It should be run in
 // order such that the preamble to a child will be executed first, then the body
 // of the child, then the postamble of the parent.
 //
 // If this finds multiple children that look "equal" (see `equals` below), it
 // will only run the first.
+//
+// Whatever time isn't spent in the child is added to this suite's replay time,
+// and anything the body writes or logs again is thrown away.
 func (s *suite) runChild(c *suite, reporter Reporter) error {
+	start := time.Now()
+	var childTotal time.Duration
+
 	childRan := false
 	s.ctx.onChild = func(child *suite) {
 		if child.equals(c) {
 			child.Run(reporter)
+			childTotal += child.Stats.Total
 			childRan = true
 		}
 	}
 	defer func() { s.ctx.onChild = nil }()
-	s.run(reporter)
+	stdout, stderr, logs := s.Stdout, s.Stderr, s.Logs
+	errs, skip := s.run(reporter)
+	s.Stdout, s.Stderr, s.Logs = stdout, stderr, logs
+	s.Stats.Replay += time.Now().Sub(start) - childTotal
 
+	// A body needn't do the same thing each time it runs. Whatever went wrong
+	// replaying it is reported against the child, as is a child it no longer
+	// declares.
+	var err error
 	if !childRan {
-		return fmt.Errorf("Found no child named '%s'", c.Name)
+		err = fmt.Errorf("Found no child named '%s'", c.Name)
+		if len(errs) == 0 && skip == nil {
+			errs = []*TestError{{Err: err, Kind: KindFail}}
+		}
+	}
+	if skip != nil && !childRan {
+		reporter.Start(c)
+		reporter.Skip(c, skip)
+	} else if len(errs) != 0 {
+		reporter.Start(c)
+		reporter.Fail(c, errs)
 	}
-	return nil
+	return err
 }
 
 // Super-hacky approximation of if two suites are equal. For the moment, this
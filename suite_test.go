@@ -1,6 +1,7 @@
 package spec
 
 import (
+	"github.com/markchadwick/assert"
 	"testing"
 	"time"
 )
@@ -32,3 +33,32 @@ func TestSuite(t *testing.T) {
 	t.Skip("Not now")
 	Run(t)
 }
+
+func TestPendingSuite(t *testing.T) {
+	Suite("Pending", nil).Run(nilReporter)
+
+	skip := nilReporter.lastSkip
+	assert.That(t, skip).NotNil()
+	assert.That(t, skip.pending).IsTrue()
+	assert.That(t, skip.Error()).Equals("pending")
+}
+
+func TestSuiteReplayTime(t *testing.T) {
+	var child *suite
+	s := Suite("Expensive setup", func(c *C) {
+		time.Sleep(2 * time.Millisecond)
+		c.It("first", func(c *C) {})
+		child = c.It("second", func(c *C) {
+			time.Sleep(time.Millisecond)
+		})
+	})
+	s.Run(nilReporter)
+
+	assert.That(t, s.Stats.Duration >= 2*time.Millisecond).IsTrue()
+	assert.That(t, s.Stats.Replay >= 4*time.Millisecond).IsTrue()
+	assert.That(t, s.Stats.Total >= s.Stats.Duration+s.Stats.Replay+time.Millisecond).
+		IsTrue()
+
+	assert.That(t, child.Stats.Replay).Equals(time.Duration(0))
+	assert.That(t, child.Stats.Total >= child.Stats.Duration).IsTrue()
+}
@@ -0,0 +1,159 @@
+package spec
+
+import (
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+)
+
+// A reporter which writes the Test Anything Protocol, version 14. Suites with
+// children are written as subtests.
+type TAPReporter struct {
+	w      io.Writer
+	levels []*tapLevel
+}
+
+// The test points written at a single level of nesting.
+type tapLevel struct {
+	name   string
+	n      int
+	failed bool
+}
+
+func TAP(w io.Writer) *TAPReporter {
+	return &TAPReporter{
+		w: w,
+	}
+}
+
+func (t *TAPReporter) Start(s *suite) {
+	level := t.level()
+	if level.n == 0 && len(t.levels) > 1 {
+		t.printf("# Subtest: %s\n", tapEscape(level.name))
+	}
+}
+
+func (t *TAPReporter) Pass(*suite) {
+	// Written on Ascend, once it is known whether any children failed
+}
+
+func (t *TAPReporter) Fail(s *suite, errs []*TestError) {
+	level := t.level()
+	level.failed = true
+	t.point(false, s.Name, "")
+
+	t.printf("  ---\n")
+	if len(errs) == 1 {
+		t.diagnostic("  ", errs[0])
+	} else {
+		t.printf("  errors:\n")
+		for _, err := range errs {
+			t.diagnostic("  - ", err)
+		}
+	}
+	t.printf("  ...\n")
+}
+
+func (t *TAPReporter) Skip(s *suite, skip *TestError) {
+	if skip.pending {
+		t.point(false, s.Name, "TODO "+skip.Error())
+	} else {
+		t.point(true, s.Name, "SKIP "+skip.Error())
+	}
+}
+
+func (t *TAPReporter) Descend(s *suite) {
+	t.levels = append(t.levels, &tapLevel{name: s.Name})
+}
+
+func (t *TAPReporter) Ascend(s *suite) {
+	level := t.level()
+	if level.n > 0 {
+		t.printf("1..%d\n", level.n)
+	}
+	t.levels = t.levels[0 : len(t.levels)-1]
+
+	if level.failed {
+		t.level().failed = true
+	}
+	t.point(!level.failed, s.Name, "")
+}
+
+func (t *TAPReporter) Begin() {
+	t.levels = []*tapLevel{&tapLevel{}}
+	fmt.Fprintf(t.w, "TAP version 14\n")
+}
+
+func (t *TAPReporter) Finish([]*SuiteFailure) {
+	t.printf("1..%d\n", t.level().n)
+}
+
+func (t *TAPReporter) level() *tapLevel {
+	return t.levels[len(t.levels)-1]
+}
+
+// Write the next test point at the current level.
+func (t *TAPReporter) point(ok bool, name, directive string) {
+	level := t.level()
+	level.n++
+
+	status := "ok"
+	if !ok {
+		status = "not ok"
+	}
+	line := fmt.Sprintf("%s %d - %s", status, level.n, tapEscape(name))
+	if directive != "" {
+		line += " # " + tapEscape(directive)
+	}
+	t.printf("%s\n", line)
+}
+
+// Write a YAML diagnostic for a single error. The first line is prefixed with
+// `lead` and the rest are aligned beneath it.
+func (t *TAPReporter) diagnostic(lead string, err *TestError) {
+	indent := strings.Repeat(" ", len(lead))
+	t.printf("%smessage: %s\n", lead, tapYAMLString(err.Error(), indent))
+	t.printf("%sseverity: fail\n", indent)
+	if err.File != "" {
+		t.printf("%sat:\n", indent)
+		t.printf("%s  file: %s\n", indent, strconv.Quote(err.File))
+		t.printf("%s  line: %d\n", indent, err.Line)
+	}
+	if src, e := err.Source(); e == nil && src != "" {
+		t.printf("%ssource: %s\n", indent, strconv.Quote(src))
+	}
+	if err.Diff != "" {
+		t.printf("%sdiff: %s\n", indent, tapYAMLString(err.Diff+"\n", indent))
+	}
+}
+
+// Print lines indented to the current level of nesting. Subtests are indented
+// four spaces for each level.
+func (t *TAPReporter) printf(f string, args ...interface{}) {
+	pad := strings.Repeat("    ", len(t.levels)-1)
+	text := strings.TrimSuffix(fmt.Sprintf(f, args...), "\n")
+	fmt.Fprintf(t.w, "%s%s\n", pad, strings.Replace(text, "\n", "\n"+pad, -1))
+}
+
+// Escape a test point's description or directive so it cannot be mistaken for
+// the start of one.
+func tapEscape(s string) string {
+	s = strings.Replace(s, `\`, `\\`, -1)
+	s = strings.Replace(s, "#", `\#`, -1)
+	return strings.Join(strings.Fields(s), " ")
+}
+
+// A YAML scalar for the given string, using a literal block for multi-line
+// strings.
+func tapYAMLString(s, indent string) string {
+	if !strings.Contains(s, "\n") {
+		return strconv.Quote(s)
+	}
+	lines := strings.Split(strings.TrimRight(s, "\n"), "\n")
+	block := "|-"
+	for _, line := range lines {
+		block += "\n" + indent + "  " + line
+	}
+	return block
+}
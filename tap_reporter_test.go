@@ -0,0 +1,62 @@
+package spec
+
+import (
+	"bytes"
+	"github.com/markchadwick/assert"
+	"regexp"
+	"strings"
+	"testing"
+)
+
+func TestTAPOutput(t *testing.T) {
+	buf := new(bytes.Buffer)
+	runFixture(TAP(buf))
+
+	lines := strings.Split(buf.String(), "\n")
+	file := lines[11][strings.Index(lines[11], `"`):]
+	assert.That(t, strings.HasSuffix(file, `reporter_test.go"`)).IsTrue()
+	line := lines[12]
+	assert.That(t, regexp.MustCompile(`^ {12}line: \d+$`).MatchString(line)).IsTrue()
+
+	assert.That(t, lines).Equals([]string{
+		"TAP version 14",
+		"    # Subtest: Fixture suite",
+		"    ok 1 - passes",
+		"    ok 2 - skips # SKIP later",
+		"    not ok 3 - is pending # TODO pending",
+		"        # Subtest: nests",
+		"        not ok 1 - fails",
+		"          ---",
+		`          message: "nope"`,
+		"          severity: fail",
+		"          at:",
+		"            file: " + file,
+		line,
+		`          source: "c.Failf(\"nope\")"`,
+		"          ...",
+		"        1..1",
+		"    not ok 4 - nests",
+		"    1..4",
+		"not ok 1 - Fixture suite",
+		"1..1",
+		"",
+	})
+}
+
+func TestTAPSuites(t *testing.T) {
+	buf := new(bytes.Buffer)
+	Runner(Suite("Lonely suite", func(c *C) {}), Suite("skips #1", func(c *C) {
+		c.Skip("later")
+	})).Run(TAP(buf))
+
+	assert.That(t, buf.String()).Equals("TAP version 14\n" +
+		"ok 1 - Lonely suite\n" +
+		"ok 2 - skips \\#1 # SKIP later\n" +
+		"1..2\n")
+}
+
+func TestTAPYAMLString(t *testing.T) {
+	assert.That(t, tapYAMLString("one line", "")).Equals(`"one line"`)
+	assert.That(t, tapYAMLString("two\nlines\n", "  ")).
+		Equals("|-\n    two\n    lines")
+}
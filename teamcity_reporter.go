@@ -0,0 +1,100 @@
+package spec
+
+import (
+	"fmt"
+	"io"
+	"strings"
+)
+
+// A reporter which writes TeamCity service messages. Every spec is reported as
+// a test, and the children of a spec are reported within a test suite of the
+// same name.
+type TeamCityReporter struct {
+	w      io.Writer
+	suites []bool
+}
+
+var teamCityEscaper = strings.NewReplacer(
+	"|", "||",
+	"'", "|'",
+	"\n", "|n",
+	"\r", "|r",
+	"[", "|[",
+	"]", "|]",
+	"\u0085", "|x",
+	"\u2028", "|l",
+	"\u2029", "|p",
+)
+
+func TeamCity(w io.Writer) *TeamCityReporter {
+	return &TeamCityReporter{
+		w:      w,
+		suites: make([]bool, 0),
+	}
+}
+
+func (t *TeamCityReporter) Start(s *suite) {
+	t.message("testStarted", "name", s.Name)
+}
+
+func (t *TeamCityReporter) Pass(s *suite) {
+	t.finished(s)
+}
+
+func (t *TeamCityReporter) Fail(s *suite, errs []*TestError) {
+	details := make([]string, len(errs))
+	for i, err := range errs {
+		details[i] = fmt.Sprintf("%s:%d", err.File, err.Line)
+		if src, e := err.Source(); e == nil {
+			details[i] += "\n  " + src
+		}
+		details[i] += "\n" + err.Error()
+	}
+
+	t.message("testFailed",
+		"name", s.Name,
+		"message", errs[0].Error(),
+		"details", strings.Join(details, "\n\n"))
+	t.finished(s)
+}
+
+func (t *TeamCityReporter) Skip(s *suite, skip *TestError) {
+	t.message("testIgnored", "name", s.Name, "message", skip.Error())
+	t.finished(s)
+}
+
+func (t *TeamCityReporter) Descend(s *suite) {
+	hasChildren := len(s.children) > 0
+	t.suites = append(t.suites, hasChildren)
+	if hasChildren {
+		t.message("testSuiteStarted", "name", s.Name)
+	}
+}
+
+func (t *TeamCityReporter) Ascend(s *suite) {
+	hasChildren := t.suites[len(t.suites)-1]
+	t.suites = t.suites[0 : len(t.suites)-1]
+	if hasChildren {
+		t.message("testSuiteFinished", "name", s.Name)
+	}
+}
+
+func (t *TeamCityReporter) Begin() {
+}
+
+func (t *TeamCityReporter) Finish([]*SuiteFailure) {
+}
+
+func (t *TeamCityReporter) finished(s *suite) {
+	ms := s.Stats.Duration.Nanoseconds() / 1e6
+	t.message("testFinished", "name", s.Name, "duration", fmt.Sprint(ms))
+}
+
+// Write a single service message with the given attribute names and values.
+func (t *TeamCityReporter) message(name string, attrs ...string) {
+	msg := "##teamcity[" + name
+	for i := 0; i+1 < len(attrs); i += 2 {
+		msg += fmt.Sprintf(" %s='%s'", attrs[i], teamCityEscaper.Replace(attrs[i+1]))
+	}
+	fmt.Fprintln(t.w, msg+"]")
+}
@@ -0,0 +1,59 @@
+package spec
+
+import (
+	"bytes"
+	"github.com/markchadwick/assert"
+	"strings"
+	"testing"
+)
+
+func TestTeamCityMessages(t *testing.T) {
+	buf := new(bytes.Buffer)
+	runFixture(TeamCity(buf))
+
+	lines := strings.Split(strings.TrimSpace(buf.String()), "\n")
+	for i, line := range lines {
+		lines[i] = strings.Split(line, " duration=")[0]
+	}
+
+	failed := lines[15]
+	assert.That(t, strings.HasPrefix(failed,
+		"##teamcity[testFailed name='fails' message='nope' details='")).IsTrue()
+	assert.That(t, strings.Contains(failed, "reporter_test.go:")).IsTrue()
+	assert.That(t, strings.HasSuffix(failed, `|n  c.Failf("nope")|nnope']`)).IsTrue()
+	assert.That(t, lines).Equals([]string{
+		"##teamcity[testStarted name='Fixture suite']",
+		"##teamcity[testFinished name='Fixture suite'",
+		"##teamcity[testSuiteStarted name='Fixture suite']",
+		"##teamcity[testStarted name='passes']",
+		"##teamcity[testFinished name='passes'",
+		"##teamcity[testStarted name='skips']",
+		"##teamcity[testIgnored name='skips' message='later']",
+		"##teamcity[testFinished name='skips'",
+		"##teamcity[testStarted name='is pending']",
+		"##teamcity[testIgnored name='is pending' message='pending']",
+		"##teamcity[testFinished name='is pending'",
+		"##teamcity[testStarted name='nests']",
+		"##teamcity[testFinished name='nests'",
+		"##teamcity[testSuiteStarted name='nests']",
+		"##teamcity[testStarted name='fails']",
+		failed,
+		"##teamcity[testFinished name='fails'",
+		"##teamcity[testSuiteFinished name='nests']",
+		"##teamcity[testSuiteFinished name='Fixture suite']",
+	})
+}
+
+func TestTeamCityFailure(t *testing.T) {
+	buf := new(bytes.Buffer)
+	Runner(Suite("Failing", func(c *C) {
+		c.Failf("it's\n[broken]")
+	})).Run(TeamCity(buf))
+
+	failed := strings.Split(buf.String(), "\n")[1]
+	assert.That(t, strings.HasPrefix(failed,
+		"##teamcity[testFailed name='Failing' message='it|'s|n|[broken|]' details='")).
+		IsTrue()
+	assert.That(t, strings.Contains(failed,
+		`|n  c.Failf("it|'s\n|[broken|]")|nit|'s|n|[broken|]']`)).IsTrue()
+}
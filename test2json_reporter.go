@@ -0,0 +1,199 @@
+package spec
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"math"
+	"strconv"
+	"strings"
+	"time"
+	"unicode"
+)
+
+// A single event in the format written by `go test -json` (see `go doc
+// test2json`).
+type TestEvent struct {
+	Time    *time.Time `json:",omitempty"`
+	Action  string
+	Package string   `json:",omitempty"`
+	Test    string   `json:",omitempty"`
+	Elapsed *float64 `json:",omitempty"`
+	Output  *string  `json:",omitempty"`
+}
+
+// A reporter which writes each spec as a subtest of a single Go test in the
+// format of `go test -json`. As specs run inside one Go test, this should be
+// written to its own file rather than standard output, where test2json would
+// treat it as plain output.
+type Test2JSONReporter struct {
+	Package string
+
+	enc     *json.Encoder
+	root    *t2jTest
+	stack   []*t2jTest
+	current *t2jTest
+}
+
+type t2jTest struct {
+	name   string
+	start  time.Time
+	failed bool
+	seen   map[string]int
+}
+
+// Report specs as subtests of the named Go test, which would usually be the
+// `Name()` of the `*testing.T` running them.
+func Test2JSON(w io.Writer, test string) *Test2JSONReporter {
+	return &Test2JSONReporter{
+		enc:  json.NewEncoder(w),
+		root: &t2jTest{name: test, seen: make(map[string]int)},
+	}
+}
+
+func (t *Test2JSONReporter) Start(s *suite) {
+	parent := t.stack[len(t.stack)-1]
+	t.current = &t2jTest{
+		name:  parent.name + "/" + parent.subtest(s.Name),
+		start: time.Now(),
+		seen:  make(map[string]int),
+	}
+	t.run(t.current)
+}
+
+func (t *Test2JSONReporter) Pass(*suite) {
+	// Wait until all children have run to say whether this passed, as Go would
+}
+
+func (t *Test2JSONReporter) Fail(s *suite, errs []*TestError) {
+	for _, err := range errs {
+		t.log(t.current, err)
+	}
+	for _, parent := range t.stack {
+		parent.failed = true
+	}
+	t.current.failed = true
+	t.done(t.current, "fail", s.Stats.Duration)
+}
+
+func (t *Test2JSONReporter) Skip(s *suite, skip *TestError) {
+	// Go writes the reason for a skip ahead of the line saying it was skipped,
+	// as it would any other log
+	t.log(t.current, skip)
+	t.done(t.current, "skip", s.Stats.Duration)
+}
+
+func (t *Test2JSONReporter) Descend(*suite) {
+	t.stack = append(t.stack, t.current)
+}
+
+func (t *Test2JSONReporter) Ascend(*suite) {
+	test := t.stack[len(t.stack)-1]
+	t.stack = t.stack[0 : len(t.stack)-1]
+	t.finish(test)
+}
+
+func (t *Test2JSONReporter) Begin() {
+	t.root.start = time.Now()
+	t.root.failed = false
+	t.root.seen = make(map[string]int)
+	t.stack = []*t2jTest{t.root}
+	t.run(t.root)
+}
+
+func (t *Test2JSONReporter) Finish([]*SuiteFailure) {
+	t.finish(t.root)
+}
+
+func (t *Test2JSONReporter) run(test *t2jTest) {
+	t.write(test, "run", nil)
+	t.output(test, fmt.Sprintf("=== RUN   %s\n", test.name))
+}
+
+func (t *Test2JSONReporter) finish(test *t2jTest) {
+	action := "pass"
+	if test.failed {
+		action = "fail"
+	}
+	t.done(test, action, time.Now().Sub(test.start))
+}
+
+// Write the closing line of a test, and the event for how it ended.
+func (t *Test2JSONReporter) done(test *t2jTest, action string, d time.Duration) {
+	elapsed := roundElapsed(d)
+	t.result(test, action, elapsed)
+	t.write(test, action, &elapsed)
+}
+
+func (t *Test2JSONReporter) result(test *t2jTest, action string, elapsed float64) {
+	t.output(test, fmt.Sprintf("--- %s: %s (%.2fs)\n",
+		strings.ToUpper(action), test.name, elapsed))
+}
+
+// Write an error as Go logs one: indented four spaces, with any further lines
+// indented four more, whatever the depth of the test.
+func (t *Test2JSONReporter) log(test *t2jTest, err *TestError) {
+	msg := strings.Replace(err.Error(), "\n", "\n        ", -1)
+	if err.File == "" {
+		t.output(test, fmt.Sprintf("    %s\n", msg))
+		return
+	}
+	t.output(test, fmt.Sprintf("    %s:%d: %s\n", shortFile(err.File), err.Line, msg))
+}
+
+func (t *Test2JSONReporter) output(test *t2jTest, out string) {
+	event := &TestEvent{Output: &out}
+	t.send(test, "output", event)
+}
+
+func (t *Test2JSONReporter) write(test *t2jTest, action string, elapsed *float64) {
+	t.send(test, action, &TestEvent{Elapsed: elapsed})
+}
+
+func (t *Test2JSONReporter) send(test *t2jTest, action string, event *TestEvent) {
+	now := time.Now()
+	event.Time = &now
+	event.Action = action
+	event.Package = t.Package
+	event.Test = test.name
+	t.enc.Encode(event)
+}
+
+// The name Go would give a subtest of the given name under this test: spaces
+// become underscores, unprintable characters are escaped, and duplicate names
+// are numbered.
+func (t *t2jTest) subtest(name string) string {
+	b := new(strings.Builder)
+	for _, r := range name {
+		switch {
+		case unicode.IsSpace(r):
+			b.WriteRune('_')
+		case !strconv.IsPrint(r):
+			s := strconv.QuoteRune(r)
+			b.WriteString(s[1 : len(s)-1])
+		default:
+			b.WriteRune(r)
+		}
+	}
+	name = b.String()
+
+	n := t.seen[name]
+	t.seen[name] = n + 1
+	if n > 0 {
+		name = fmt.Sprintf("%s#%02d", name, n)
+	}
+	return name
+}
+
+// Elapsed seconds, to the precision `go test` prints them.
+func roundElapsed(d time.Duration) float64 {
+	return math.Round(d.Seconds()*100) / 100
+}
+
+// The base name of a file, as `go test` prints it.
+func shortFile(file string) string {
+	if i := strings.LastIndex(file, "/"); i >= 0 {
+		return file[i+1:]
+	}
+	return file
+}
@@ -0,0 +1,92 @@
+package spec
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"github.com/markchadwick/assert"
+	"strings"
+	"testing"
+)
+
+func TestTest2JSONEvents(t *testing.T) {
+	buf := new(bytes.Buffer)
+	runFixture(Test2JSON(buf, "TestSpecs"))
+
+	actions := make([]string, 0)
+	outputs := make([]string, 0)
+	scanner := bufio.NewScanner(buf)
+	for scanner.Scan() {
+		event := new(TestEvent)
+		assert.That(t, json.Unmarshal(scanner.Bytes(), event)).IsNil()
+		if event.Action == "output" {
+			outputs = append(outputs, *event.Output)
+		} else {
+			actions = append(actions, event.Action+" "+event.Test)
+		}
+	}
+
+	assert.That(t, actions).Equals([]string{
+		"run TestSpecs",
+		"run TestSpecs/Fixture_suite",
+		"run TestSpecs/Fixture_suite/passes",
+		"pass TestSpecs/Fixture_suite/passes",
+		"run TestSpecs/Fixture_suite/skips",
+		"skip TestSpecs/Fixture_suite/skips",
+		"run TestSpecs/Fixture_suite/is_pending",
+		"skip TestSpecs/Fixture_suite/is_pending",
+		"run TestSpecs/Fixture_suite/nests",
+		"run TestSpecs/Fixture_suite/nests/fails",
+		"fail TestSpecs/Fixture_suite/nests/fails",
+		"fail TestSpecs/Fixture_suite/nests",
+		"fail TestSpecs/Fixture_suite",
+		"fail TestSpecs",
+	})
+
+	assert.That(t, outputs[0]).Equals("=== RUN   TestSpecs\n")
+	assert.That(t, strings.HasPrefix(outputs[5], "    reporter_test.go:")).IsTrue()
+	assert.That(t, strings.HasSuffix(outputs[5], ": later\n")).IsTrue()
+	assert.That(t, outputs[6]).
+		Equals("--- SKIP: TestSpecs/Fixture_suite/skips (0.00s)\n")
+	assert.That(t, outputs[8]).Equals("    pending\n")
+	assert.That(t, strings.HasPrefix(outputs[12], "    reporter_test.go:")).IsTrue()
+	assert.That(t, strings.HasSuffix(outputs[12], ": nope\n")).IsTrue()
+	assert.That(t, outputs[13]).
+		Equals("--- FAIL: TestSpecs/Fixture_suite/nests/fails (0.00s)\n")
+}
+
+func TestTest2JSONSubtestNames(t *testing.T) {
+	test := &t2jTest{seen: make(map[string]int)}
+	assert.That(t, test.subtest("has spaces")).Equals("has_spaces")
+	assert.That(t, test.subtest("has spaces")).Equals("has_spaces#01")
+	assert.That(t, test.subtest("bell\a")).Equals(`bell\a`)
+}
+
+func TestTest2JSONNestedNames(t *testing.T) {
+	buf := new(bytes.Buffer)
+	Runner(Suite("A suite", func(c *C) {
+		c.It("a/b", func(c *C) {
+			c.It("fails", func(c *C) {
+				c.Failf("nope\nat all")
+			})
+		})
+	})).Run(Test2JSON(buf, "TestSpecs"))
+
+	outputs := make(map[string][]string)
+	scanner := bufio.NewScanner(buf)
+	for scanner.Scan() {
+		event := new(TestEvent)
+		assert.That(t, json.Unmarshal(scanner.Bytes(), event)).IsNil()
+		if event.Action == "output" {
+			outputs[event.Test] = append(outputs[event.Test], *event.Output)
+		}
+	}
+
+	// Nested or not, results aren't indented, and logs are indented once
+	fails := outputs["TestSpecs/A_suite/a/b/fails"]
+	assert.That(t, strings.HasPrefix(fails[1], "    test2json_reporter_test.go:")).IsTrue()
+	assert.That(t, strings.HasSuffix(fails[1], ": nope\n        at all\n")).IsTrue()
+	assert.That(t, fails[2]).Equals("--- FAIL: TestSpecs/A_suite/a/b/fails (0.00s)\n")
+	assert.That(t, outputs["TestSpecs/A_suite/a/b"][1]).
+		Equals("--- FAIL: TestSpecs/A_suite/a/b (0.00s)\n")
+}